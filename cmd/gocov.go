@@ -10,6 +10,7 @@ import (
 	"io/fs"
 	"os"
 	"path"
+	"strconv"
 	"strings"
 
 	"github.com/slavsan/gocov/internal"
@@ -19,11 +20,55 @@ const (
 	// report flags.
 	reportFileFlagDesc = "coverage profile file (default is coverage.out)"
 	depthFlagDesc      = "report on files and directories of certain depth"
-	noColorFlagDesc    = "disable color output"
+	noColorFlagDesc    = "disable color output, same as --color=never"
+	colorFlagDesc      = "use colors: auto, always or never (default is auto)"
 	withFullPathDesc   = "include the full path column in the output"
 	htmlOutputFlagDesc = "output the coverage in html format"
+	htmlDirFlagDesc    = "output the coverage as an html site with a page per directory and file in the given directory"
+	outputFlagDesc     = "write the report to the given file, - for stdout (default is stdout, coverage.html for html)"
+	themeFlagDesc      = "html theme: light, dark or high-contrast (default is light)"
+	sortFlagDesc       = "sort by name, percent, uncovered or stmts (default is name)"
+	reverseFlagDesc    = "reverse the sort order"
+	minFlagDesc        = "hide files with coverage below the given percent"
+	maxFlagDesc        = "hide files with coverage above the given percent"
+	onlyUncoveredDesc  = "hide files which are fully covered"
+	topFlagDesc        = "show only the first N files in the sort order"
+	flatFlagDesc       = "list the files with their full paths instead of a tree"
+	compactFlagDesc    = "collapse chains of single child directories (default is true for html)"
+	styleFlagDesc      = "table style: ascii, unicode, rounded or none (default is ascii)"
+	widthFlagDesc      = "maximum table width (default is the terminal width)"
+	barWidthFlagDesc   = "width of the progress bar"
+	barSymbolFlagDesc  = "symbol used to fill the progress bar"
+	uncovLinesFlagDesc = "include the uncovered line ranges column in the output"
+	linesFlagDesc      = "include the line coverage column in the output"
+	branchesFlagDesc   = "include the branch coverage column in the output"
+	exportedFlagDesc   = "report the coverage of the exported functions, methods and types"
+	deadFlagDesc       = "list the uncovered functions as unreferenced or untested"
 	// inspect flags.
-	exactFlagDesc = "specify exact path to file"
+	exactFlagDesc         = "specify exact path to file"
+	uncoveredOnlyFlagDesc = "print only the lines around the uncovered blocks"
+	contextFlagDesc       = "number of context lines around the uncovered blocks"
+	listFlagDesc          = "print only the matching files and their coverage"
+	countsFlagDesc        = "show the hit count of each line in the gutter"
+	heatFlagDesc          = "color the hit counts by how hot the lines are (implies --counts)"
+	funcFlagDesc          = "inspect only the function or method with the given name"
+	highlightFlagDesc     = "highlight the syntax, the uncovered code is shown on a red background"
+	markCoveredFlagDesc   = "mark the covered lines in the gutter"
+	markUncoveredFlagDesc = "mark the lines with uncovered statements in the gutter"
+	testsFlagDesc         = "annotate the lines with the tests covering them, see test --per-test"
+	cacheDirFlagDesc      = "directory of the per-test coverage profiles"
+	// test flags.
+	perTestFlagDesc = "also run every test on its own to record which tests cover each block"
+	// hotspots flags.
+	hotspotsTopFlagDesc = "number of functions to show (default is 10)"
+	// affected flags.
+	diffFlagDesc  = "git revision to diff the working tree against"
+	patchFlagDesc = "read the changes from a unified diff file instead of running git diff"
+	// stability flags.
+	runsFlagDesc = "number of times to run the tests (default is 3)"
+	// mutate flags.
+	listMutantsFlagDesc = "only list the mutants without running the tests"
+	timeoutFlagDesc     = "timeout of the tests of a single mutant (default is 30s)"
 	// check flags.
 	thresholdFlagDesc = "specify the desired coverage threshold"
 )
@@ -34,21 +79,63 @@ func Exec() { //nolint:funlen
 		command internal.Command
 		args    []string
 		config  = &internal.Config{
-			Color:  true,
 			Global: loadGlobalConf(),
 		}
-		reportFile   string
-		reportDepth  int
-		noColor      bool
-		withFullPath bool
-		exactPath    bool
-		threshold    float64
-		htmlOutput   bool
-
-		reportCmd  = flag.NewFlagSet("report", flag.ExitOnError)
-		checkCmd   = flag.NewFlagSet("check", flag.ExitOnError)
-		inspectCmd = flag.NewFlagSet("inspect", flag.ExitOnError)
-		configCmd  = flag.NewFlagSet("config", flag.ExitOnError)
+		reportFile    string
+		reportDepth   int
+		noColor       bool
+		colorMode     string
+		withFullPath  bool
+		exactPath     bool
+		threshold     float64
+		htmlOutput    bool
+		htmlDir       string
+		output        string
+		theme         string
+		uncovOnly     bool
+		context       int
+		listFiles     bool
+		counts        bool
+		heat          bool
+		funcName      string
+		highlight     bool
+		markCovered   bool
+		markUncovered bool
+		uncovLines    bool
+		lines         bool
+		branches      bool
+		exported      bool
+		dead          bool
+		perTest       bool
+		tests         bool
+		cacheDir      string
+		diff          string
+		patch         string
+		runs          int
+		listMutants   bool
+		timeout       string
+		sortBy        string
+		reverse       bool
+		minPercent    float64
+		maxPercent    float64
+		onlyUncov     bool
+		top           int
+		flat          bool
+		compact       bool
+		style         string
+		width         int
+		barWidth      int
+		barSymbol     string
+
+		reportCmd    = flag.NewFlagSet("report", flag.ExitOnError)
+		checkCmd     = flag.NewFlagSet("check", flag.ExitOnError)
+		inspectCmd   = flag.NewFlagSet("inspect", flag.ExitOnError)
+		configCmd    = flag.NewFlagSet("config", flag.ExitOnError)
+		hotspotsCmd  = flag.NewFlagSet("hotspots", flag.ExitOnError)
+		testCmd      = flag.NewFlagSet("test", flag.ExitOnError)
+		affectedCmd  = flag.NewFlagSet("affected", flag.ExitOnError)
+		stabilityCmd = flag.NewFlagSet("stability", flag.ExitOnError)
+		mutateCmd    = flag.NewFlagSet("mutate", flag.ExitOnError)
 	)
 
 	reportCmd.StringVar(&reportFile, "file", "coverage.out", reportFileFlagDesc)
@@ -56,14 +143,89 @@ func Exec() { //nolint:funlen
 	reportCmd.IntVar(&reportDepth, "depth", 0, depthFlagDesc)
 	reportCmd.IntVar(&reportDepth, "d", 0, depthFlagDesc)
 	reportCmd.BoolVar(&noColor, "no-color", false, noColorFlagDesc)
+	reportCmd.StringVar(&colorMode, "color", internal.ColorAuto, colorFlagDesc)
 	reportCmd.BoolVar(&withFullPath, "with-full-path", false, noColorFlagDesc)
 	reportCmd.BoolVar(&htmlOutput, "html", false, htmlOutputFlagDesc)
+	reportCmd.StringVar(&htmlDir, "html-dir", "", htmlDirFlagDesc)
+	reportCmd.StringVar(&output, "output", "", outputFlagDesc)
+	reportCmd.StringVar(&output, "o", "", outputFlagDesc)
+	reportCmd.StringVar(&theme, "theme", "", themeFlagDesc)
+	reportCmd.StringVar(&sortBy, "sort", internal.SortByName, sortFlagDesc)
+	reportCmd.BoolVar(&reverse, "reverse", false, reverseFlagDesc)
+	reportCmd.Float64Var(&minPercent, "min", 0, minFlagDesc)
+	reportCmd.Float64Var(&maxPercent, "max", 0, maxFlagDesc)
+	reportCmd.BoolVar(&onlyUncov, "only-uncovered", false, onlyUncoveredDesc)
+	reportCmd.IntVar(&top, "top", 0, topFlagDesc)
+	reportCmd.BoolVar(&flat, "flat", false, flatFlagDesc)
+	reportCmd.BoolVar(&compact, "compact", false, compactFlagDesc)
+	reportCmd.StringVar(&style, "style", internal.StyleASCII, styleFlagDesc)
+	reportCmd.IntVar(&width, "width", 0, widthFlagDesc)
+	reportCmd.IntVar(&barWidth, "bar-width", 10, barWidthFlagDesc)
+	reportCmd.StringVar(&barSymbol, "bar-symbol", "\u25A0", barSymbolFlagDesc)
+	reportCmd.BoolVar(&uncovLines, "uncovered-lines", false, uncovLinesFlagDesc)
+	reportCmd.BoolVar(&lines, "lines", false, linesFlagDesc)
+	reportCmd.BoolVar(&branches, "branches", false, branchesFlagDesc)
+	reportCmd.BoolVar(&exported, "exported", false, exportedFlagDesc)
+	reportCmd.BoolVar(&dead, "dead", false, deadFlagDesc)
+	reportCmd.BoolVar(&tests, "tests", false, testsFlagDesc)
+	reportCmd.StringVar(&cacheDir, "cache-dir", ".gocov-cache", cacheDirFlagDesc)
+	reportCmd.BoolVar(&counts, "counts", false, countsFlagDesc)
+	reportCmd.BoolVar(&heat, "heat", false, heatFlagDesc)
 
 	checkCmd.Float64Var(&threshold, "threshold", 0, thresholdFlagDesc)
 	checkCmd.StringVar(&reportFile, "file", "coverage.out", reportFileFlagDesc)
 	checkCmd.StringVar(&reportFile, "f", "coverage.out", reportFileFlagDesc)
+	checkCmd.StringVar(&colorMode, "color", internal.ColorAuto, colorFlagDesc)
 
 	inspectCmd.BoolVar(&exactPath, "exact", false, noColorFlagDesc)
+	inspectCmd.StringVar(&colorMode, "color", internal.ColorAuto, colorFlagDesc)
+	inspectCmd.BoolVar(&uncovOnly, "uncovered-only", false, uncoveredOnlyFlagDesc)
+	inspectCmd.IntVar(&context, "context", 3, contextFlagDesc)
+	inspectCmd.IntVar(&context, "C", 3, contextFlagDesc)
+	inspectCmd.BoolVar(&listFiles, "list", false, listFlagDesc)
+	inspectCmd.BoolVar(&counts, "counts", false, countsFlagDesc)
+	inspectCmd.BoolVar(&heat, "heat", false, heatFlagDesc)
+	inspectCmd.StringVar(&funcName, "func", "", funcFlagDesc)
+	inspectCmd.BoolVar(&tests, "tests", false, testsFlagDesc)
+	inspectCmd.StringVar(&cacheDir, "cache-dir", ".gocov-cache", cacheDirFlagDesc)
+	inspectCmd.BoolVar(&highlight, "highlight", false, highlightFlagDesc)
+	inspectCmd.BoolVar(&markCovered, "mark-covered", false, markCoveredFlagDesc)
+	inspectCmd.BoolVar(&markUncovered, "mark-uncovered", false, markUncoveredFlagDesc)
+
+	configCmd.StringVar(&colorMode, "color", internal.ColorAuto, colorFlagDesc)
+
+	testCmd.BoolVar(&perTest, "per-test", false, perTestFlagDesc)
+	testCmd.StringVar(&cacheDir, "cache-dir", ".gocov-cache", cacheDirFlagDesc)
+	testCmd.StringVar(&colorMode, "color", internal.ColorAuto, colorFlagDesc)
+
+	hotspotsCmd.StringVar(&reportFile, "file", "coverage.out", reportFileFlagDesc)
+	hotspotsCmd.StringVar(&reportFile, "f", "coverage.out", reportFileFlagDesc)
+	hotspotsCmd.IntVar(&top, "top", 0, hotspotsTopFlagDesc)
+	hotspotsCmd.StringVar(&colorMode, "color", internal.ColorAuto, colorFlagDesc)
+	hotspotsCmd.StringVar(&style, "style", internal.StyleASCII, styleFlagDesc)
+	hotspotsCmd.IntVar(&width, "width", 0, widthFlagDesc)
+
+	affectedCmd.StringVar(&reportFile, "file", "coverage.out", reportFileFlagDesc)
+	affectedCmd.StringVar(&reportFile, "f", "coverage.out", reportFileFlagDesc)
+	affectedCmd.StringVar(&diff, "diff", "HEAD", diffFlagDesc)
+	affectedCmd.StringVar(&patch, "patch", "", patchFlagDesc)
+	affectedCmd.StringVar(&cacheDir, "cache-dir", ".gocov-cache", cacheDirFlagDesc)
+	affectedCmd.StringVar(&colorMode, "color", internal.ColorAuto, colorFlagDesc)
+
+	stabilityCmd.IntVar(&runs, "runs", 0, runsFlagDesc)
+	stabilityCmd.StringVar(&cacheDir, "cache-dir", ".gocov-cache", cacheDirFlagDesc)
+	stabilityCmd.StringVar(&colorMode, "color", internal.ColorAuto, colorFlagDesc)
+	stabilityCmd.StringVar(&style, "style", internal.StyleASCII, styleFlagDesc)
+	stabilityCmd.IntVar(&width, "width", 0, widthFlagDesc)
+
+	mutateCmd.StringVar(&reportFile, "file", "coverage.out", reportFileFlagDesc)
+	mutateCmd.StringVar(&reportFile, "f", "coverage.out", reportFileFlagDesc)
+	mutateCmd.BoolVar(&exactPath, "exact", false, exactFlagDesc)
+	mutateCmd.BoolVar(&listMutants, "list", false, listMutantsFlagDesc)
+	mutateCmd.StringVar(&timeout, "timeout", "", timeoutFlagDesc)
+	mutateCmd.StringVar(&colorMode, "color", internal.ColorAuto, colorFlagDesc)
+	mutateCmd.StringVar(&style, "style", internal.StyleASCII, styleFlagDesc)
+	mutateCmd.IntVar(&width, "width", 0, widthFlagDesc)
 
 	reportCmd.Usage = func() {
 		_, _ = fmt.Fprintf(
@@ -75,14 +237,68 @@ func Exec() { //nolint:funlen
 				`      %s`,
 				`  --html`,
 				`      %s`,
+				`  --html-dir string`,
+				`      %s`,
+				`  -o, --output string`,
+				`      %s`,
+				`  --theme string`,
+				`      %s`,
+				`  --color string`,
+				`      %s`,
 				`  --no-color`,
 				`      %s`,
 				`  --with-full-path`,
 				`      %s`,
+				`  --sort string`,
+				`      %s`,
+				`  --reverse`,
+				`      %s`,
+				`  --min float`,
+				`      %s`,
+				`  --max float`,
+				`      %s`,
+				`  --only-uncovered`,
+				`      %s`,
+				`  --top int`,
+				`      %s`,
+				`  --flat`,
+				`      %s`,
+				`  --compact`,
+				`      %s`,
+				`  --style string`,
+				`      %s`,
+				`  --width int`,
+				`      %s`,
+				`  --bar-width int`,
+				`      %s`,
+				`  --bar-symbol string`,
+				`      %s`,
+				`  --uncovered-lines`,
+				`      %s`,
+				`  --lines`,
+				`      %s`,
+				`  --branches`,
+				`      %s`,
+				`  --exported`,
+				`      %s`,
+				`  --dead`,
+				`      %s`,
+				`  --tests`,
+				`      %s`,
+				`  --cache-dir string`,
+				`      %s`,
+				`  --counts`,
+				`      %s`,
+				`  --heat`,
+				`      %s`,
 				``,
 			}, "\n"),
-			reportFileFlagDesc, depthFlagDesc, htmlOutputFlagDesc,
-			noColorFlagDesc, withFullPathDesc,
+			reportFileFlagDesc, depthFlagDesc, htmlOutputFlagDesc, htmlDirFlagDesc, outputFlagDesc,
+			themeFlagDesc, colorFlagDesc, noColorFlagDesc, withFullPathDesc, sortFlagDesc, reverseFlagDesc,
+			minFlagDesc, maxFlagDesc, onlyUncoveredDesc, topFlagDesc, flatFlagDesc,
+			compactFlagDesc, styleFlagDesc, widthFlagDesc, barWidthFlagDesc, barSymbolFlagDesc,
+			uncovLinesFlagDesc, linesFlagDesc, branchesFlagDesc, exportedFlagDesc, deadFlagDesc, testsFlagDesc, cacheDirFlagDesc,
+			countsFlagDesc, heatFlagDesc,
 		)
 	}
 
@@ -92,9 +308,165 @@ func Exec() { //nolint:funlen
 				`Usage of inspect:`,
 				`  --exact`,
 				`      %s`,
+				`  --color string`,
+				`      %s`,
+				`  --uncovered-only`,
+				`      %s`,
+				`  -C, --context int`,
+				`      %s`,
+				`  --list`,
+				`      %s`,
+				`  --counts`,
+				`      %s`,
+				`  --heat`,
+				`      %s`,
+				`  --func string`,
+				`      %s`,
+				`  --highlight`,
+				`      %s`,
+				`  --mark-covered`,
+				`      %s`,
+				`  --mark-uncovered`,
+				`      %s`,
+				`  --tests`,
+				`      %s`,
+				`  --cache-dir string`,
+				`      %s`,
 				``,
 			}, "\n"),
 			exactFlagDesc,
+			colorFlagDesc,
+			uncoveredOnlyFlagDesc,
+			contextFlagDesc,
+			listFlagDesc,
+			countsFlagDesc,
+			heatFlagDesc,
+			funcFlagDesc,
+			highlightFlagDesc,
+			markCoveredFlagDesc,
+			markUncoveredFlagDesc,
+			testsFlagDesc,
+			cacheDirFlagDesc,
+		)
+	}
+
+	testCmd.Usage = func() {
+		_, _ = fmt.Fprintf(
+			os.Stdout, strings.Join([]string{
+				`Usage of test:`,
+				`  --per-test`,
+				`      %s`,
+				`  --cache-dir string`,
+				`      %s`,
+				`  --color string`,
+				`      %s`,
+				``,
+			}, "\n"),
+			perTestFlagDesc,
+			cacheDirFlagDesc,
+			colorFlagDesc,
+		)
+	}
+
+	hotspotsCmd.Usage = func() {
+		_, _ = fmt.Fprintf(
+			os.Stdout, strings.Join([]string{
+				`Usage of hotspots:`,
+				`  -f, --file string`,
+				`      %s`,
+				`  --top int`,
+				`      %s`,
+				`  --color string`,
+				`      %s`,
+				`  --style string`,
+				`      %s`,
+				`  --width int`,
+				`      %s`,
+				``,
+			}, "\n"),
+			reportFileFlagDesc,
+			hotspotsTopFlagDesc,
+			colorFlagDesc,
+			styleFlagDesc,
+			widthFlagDesc,
+		)
+	}
+
+	affectedCmd.Usage = func() {
+		_, _ = fmt.Fprintf(
+			os.Stdout, strings.Join([]string{
+				`Usage of affected:`,
+				`  -f, --file string`,
+				`      %s`,
+				`  --diff string`,
+				`      %s`,
+				`  --patch string`,
+				`      %s`,
+				`  --cache-dir string`,
+				`      %s`,
+				`  --color string`,
+				`      %s`,
+				``,
+			}, "\n"),
+			reportFileFlagDesc,
+			diffFlagDesc,
+			patchFlagDesc,
+			cacheDirFlagDesc,
+			colorFlagDesc,
+		)
+	}
+
+	stabilityCmd.Usage = func() {
+		_, _ = fmt.Fprintf(
+			os.Stdout, strings.Join([]string{
+				`Usage of stability:`,
+				`  --runs int`,
+				`      %s`,
+				`  --cache-dir string`,
+				`      %s`,
+				`  --color string`,
+				`      %s`,
+				`  --style string`,
+				`      %s`,
+				`  --width int`,
+				`      %s`,
+				``,
+			}, "\n"),
+			runsFlagDesc,
+			cacheDirFlagDesc,
+			colorFlagDesc,
+			styleFlagDesc,
+			widthFlagDesc,
+		)
+	}
+
+	mutateCmd.Usage = func() {
+		_, _ = fmt.Fprintf(
+			os.Stdout, strings.Join([]string{
+				`Usage of mutate:`,
+				`  -f, --file string`,
+				`      %s`,
+				`  --exact`,
+				`      %s`,
+				`  --list`,
+				`      %s`,
+				`  --timeout string`,
+				`      %s`,
+				`  --color string`,
+				`      %s`,
+				`  --style string`,
+				`      %s`,
+				`  --width int`,
+				`      %s`,
+				``,
+			}, "\n"),
+			reportFileFlagDesc,
+			exactFlagDesc,
+			listMutantsFlagDesc,
+			timeoutFlagDesc,
+			colorFlagDesc,
+			styleFlagDesc,
+			widthFlagDesc,
 		)
 	}
 
@@ -106,10 +478,13 @@ func Exec() { //nolint:funlen
 				`      %s`,
 				`  -f, --file string`,
 				`      %s`,
+				`  --color string`,
+				`      %s`,
 				``,
 			}, "\n"),
 			thresholdFlagDesc,
 			reportFileFlagDesc,
+			colorFlagDesc,
 		)
 	}
 
@@ -131,13 +506,57 @@ func Exec() { //nolint:funlen
 			os.Exit(1)
 		}
 		config.Depth = reportDepth
-		config.Color = !noColor
+		if noColor {
+			colorMode = internal.ColorNever
+		}
 		config.WithFullPath = withFullPath
 		config.ReportFile = reportFile
-		config.HTMLOutput = htmlOutput
+		config.HTMLOutput = htmlOutput || htmlDir != ""
+		config.HTMLDir = htmlDir
+		config.Output = output
+		config.Theme = theme
+		config.SortBy = sortBy
+		config.Reverse = reverse
+		if isFlagPassed(reportCmd, "min") {
+			config.MinPercent = &minPercent
+		}
+		if isFlagPassed(reportCmd, "max") {
+			config.MaxPercent = &maxPercent
+		}
+		config.OnlyUncovered = onlyUncov
+		config.Top = top
+		config.Flat = flat
+		config.Compact = compact
+		if config.HTMLOutput && !isFlagPassed(reportCmd, "compact") {
+			config.Compact = true
+		}
+		config.Style = style
+		config.Width = width
+		if width == 0 {
+			config.Width = terminalWidth()
+		}
+		config.BarWidth = barWidth
+		config.BarSymbol = barSymbol
+		config.UncoveredLines = uncovLines
+		config.Lines = lines
+		config.Branches = branches
+		config.Exported = exported
+		config.Dead = dead
+		config.Tests = tests
+		config.CacheDir = cacheDir
+		config.Counts = counts || heat
+		config.Heat = heat
 		args = reportCmd.Args()
 	case "test":
 		command = internal.Test
+		err = testCmd.Parse(os.Args[2:])
+		if err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "failed to parse args: %s", err.Error())
+			printUsage()
+			os.Exit(1)
+		}
+		config.PerTest = perTest
+		config.CacheDir = cacheDir
 	case "config":
 		command = internal.ConfigFile
 		err = configCmd.Parse(os.Args[2:])
@@ -167,12 +586,93 @@ func Exec() { //nolint:funlen
 			os.Exit(1)
 		}
 		config.ExactPath = exactPath
+		config.UncoveredOnly = uncovOnly
+		config.Context = context
+		config.ListFiles = listFiles
+		config.Func = funcName
+		config.Highlight = highlight
+		config.MarkCovered = markCovered
+		config.MarkUncovered = markUncovered
+		config.Tests = tests
+		config.CacheDir = cacheDir
+		config.Counts = counts || heat
+		config.Heat = heat
 		args = inspectCmd.Args()
+	case "hotspots":
+		command = internal.Hotspots
+		err = hotspotsCmd.Parse(os.Args[2:])
+		if err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "failed to parse args: %s", err.Error())
+			printUsage()
+			os.Exit(1)
+		}
+		config.ReportFile = reportFile
+		config.Top = top
+		config.Style = style
+		config.Width = width
+		if width == 0 {
+			config.Width = terminalWidth()
+		}
+		args = hotspotsCmd.Args()
+	case "affected":
+		command = internal.Affected
+		err = affectedCmd.Parse(os.Args[2:])
+		if err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "failed to parse args: %s", err.Error())
+			printUsage()
+			os.Exit(1)
+		}
+		config.ReportFile = reportFile
+		config.Diff = diff
+		config.Patch = patch
+		config.CacheDir = cacheDir
+		args = affectedCmd.Args()
+	case "stability":
+		command = internal.Stability
+		err = stabilityCmd.Parse(os.Args[2:])
+		if err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "failed to parse args: %s", err.Error())
+			printUsage()
+			os.Exit(1)
+		}
+		config.Runs = runs
+		config.CacheDir = cacheDir
+		config.Style = style
+		config.Width = width
+		if width == 0 {
+			config.Width = terminalWidth()
+		}
+		args = stabilityCmd.Args()
+	case "mutate":
+		command = internal.Mutate
+		err = mutateCmd.Parse(os.Args[2:])
+		if err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "failed to parse args: %s", err.Error())
+			printUsage()
+			os.Exit(1)
+		}
+		config.ReportFile = reportFile
+		config.ExactPath = exactPath
+		config.ListMutants = listMutants
+		config.Timeout = timeout
+		config.Style = style
+		config.Width = width
+		if width == 0 {
+			config.Width = terminalWidth()
+		}
+		args = mutateCmd.Args()
 	default:
 		printUsage()
 		return
 	}
 
+	config.Color, err = internal.ColorEnabled(colorMode, os.Getenv("NO_COLOR"), isTerminal(os.Stdout))
+	if err != nil {
+		_, _ = fmt.Fprint(os.Stderr, err.Error())
+		os.Exit(1)
+	}
+	config.ErrColor, _ = internal.ColorEnabled(colorMode, os.Getenv("NO_COLOR"), isTerminal(os.Stderr))
+
 	internal.
 		NewCommand(os.Stdout, os.Stderr, os.DirFS(".").(fs.StatFS), config, &internal.ProcessExiter{}, &internal.FileWriter{}). //nolint:forcetypeassert
 		Exec(command, args)
@@ -180,18 +680,40 @@ func Exec() { //nolint:funlen
 
 const usage = `gocov - Go coverage reporting tool
 
-  test     - run tests with coverage
-  report   - print out a coverage report to stdout
-  check    - check whether the defined coverage requirements are met
-  inspect  - show the covered vs not covered statements in a file
-  config   - print a default config or the current config if one is defined
-  help     - show this help message
+  test      - run tests with coverage
+  report    - print out a coverage report to stdout
+  check     - check whether the defined coverage requirements are met
+  inspect   - show the covered vs not covered statements in a file
+  hotspots  - list the functions with the highest CRAP score
+  affected  - print the go test commands for the tests affected by a diff
+  stability - run the tests several times and report the blocks hit only sometimes
+  mutate    - check that the tests catch small changes to the covered code
+  config    - print a default config or the current config if one is defined
+  help      - show this help message
 `
 
 func printUsage() {
 	_, _ = fmt.Fprint(os.Stdout, usage)
 }
 
+func isTerminal(f *os.File) bool {
+	fi, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return fi.Mode()&os.ModeCharDevice != 0
+}
+
+func isFlagPassed(flagSet *flag.FlagSet, name string) bool {
+	var found bool
+	flagSet.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			found = true
+		}
+	})
+	return found
+}
+
 func loadGlobalConf() *internal.GocovConfig {
 	homedir, err := os.UserHomeDir()
 	if err != nil {
@@ -235,3 +757,13 @@ func loadGlobalConf() *internal.GocovConfig {
 
 	return conf
 }
+
+// terminalWidth returns the width the tables should fit in. An explicit
+// COLUMNS environment variable wins over the detected terminal size, and
+// zero is returned when the output is not a terminal.
+func terminalWidth() int {
+	if columns, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && columns > 0 {
+		return columns
+	}
+	return ttyWidth(os.Stdout)
+}
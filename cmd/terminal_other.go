@@ -0,0 +1,11 @@
+//go:build !(linux || darwin || freebsd || netbsd || openbsd || dragonfly)
+
+package cmd
+
+import "os"
+
+// ttyWidth is not supported on this platform, the width can still be set
+// with the COLUMNS environment variable or the --width flag.
+func ttyWidth(_ *os.File) int {
+	return 0
+}
@@ -0,0 +1,260 @@
+package internal
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os/exec"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// changedFile is a file of the diff, relative to the module root, with the
+// lines changed on the old side of the diff, which is the revision the
+// per-test coverage should be recorded at.
+type changedFile struct {
+	path    string
+	lines   map[int]bool
+	added   bool
+	deleted bool
+}
+
+var hunkHeaderPattern = regexp.MustCompile(`^@@ -(\d+)(?:,(\d+))? \+\d+(?:,\d+)? @@`)
+
+// parseDiff returns the files changed in a unified diff, in their order in
+// the diff.
+func parseDiff(diff string) ([]*changedFile, error) {
+	var (
+		files   []*changedFile
+		current *changedFile
+		oldPath string
+	)
+	scanner := bufio.NewScanner(strings.NewReader(diff))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "--- "):
+			oldPath = diffPath(line[4:])
+		case strings.HasPrefix(line, "+++ "):
+			newPath := diffPath(line[4:])
+			current = &changedFile{path: newPath, lines: map[int]bool{}, added: oldPath == ""}
+			if newPath == "" {
+				current.path, current.deleted = oldPath, true
+			}
+			files = append(files, current)
+		case strings.HasPrefix(line, "@@ ") && current != nil:
+			matches := hunkHeaderPattern.FindStringSubmatch(line)
+			if matches == nil {
+				return nil, fmt.Errorf("invalid hunk header in diff: %s", line) //nolint:goerr113
+			}
+			start, _ := strconv.Atoi(matches[1])
+			count := 1
+			if matches[2] != "" {
+				count, _ = strconv.Atoi(matches[2])
+			}
+			// lines added between two old lines change the blocks around them
+			if count == 0 {
+				current.lines[start] = true
+				current.lines[start+1] = true
+				continue
+			}
+			for l := start; l < start+count; l++ {
+				current.lines[l] = true
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read diff: %w", err)
+	}
+	return files, nil
+}
+
+// diffPath strips the a/ or b/ prefix of a path in the diff, /dev/null
+// becomes an empty path.
+func diffPath(name string) string {
+	if i := strings.IndexByte(name, '\t'); i != -1 {
+		name = name[:i]
+	}
+	if name == "/dev/null" {
+		return ""
+	}
+	if strings.HasPrefix(name, "a/") || strings.HasPrefix(name, "b/") {
+		return name[2:]
+	}
+	return name
+}
+
+func (cmd *Cmd) readDiff() (string, error) {
+	if cmd.config.Patch != "" {
+		b, err := fs.ReadFile(cmd.fsys, cmd.config.Patch)
+		if err != nil {
+			return "", fmt.Errorf("failed to read patch: %w", err)
+		}
+		return string(b), nil
+	}
+	diffArgs := []string{"diff", "--relative", "--unified=0", "--no-color", "--no-ext-diff", cmd.config.Diff}
+	var out bytes.Buffer
+	diffCmd := exec.Command("git", diffArgs...)
+	diffCmd.Stdout = &out
+	diffCmd.Stderr = cmd.stderr
+	if err := diffCmd.Run(); err != nil {
+		return "", fmt.Errorf("failed to run `git %s`: %w", strings.Join(diffArgs, " "), err)
+	}
+	return out.String(), nil
+}
+
+// affectedTests returns the tests per package which exercise the changed
+// code. A nil result means all of the tests have to run, the reason tells
+// why.
+func (cmd *Cmd) affectedTests(changes []*changedFile, files map[string]*covFile, module string, index *testIndex) (map[string][]string, string) {
+	names := map[string]string{}
+	for _, target := range allTargets(files, path.Dir(module)) {
+		names[target.path] = target.file.Name
+	}
+
+	tests := map[string]map[string]bool{}
+	add := func(pkg, name string) {
+		if tests[pkg] == nil {
+			tests[pkg] = map[string]bool{}
+		}
+		tests[pkg][name] = true
+	}
+
+	for _, change := range changes {
+		switch {
+		case change.path == "go.mod" || change.path == "go.sum":
+			return nil, change.path + " changed"
+		case !strings.HasSuffix(change.path, ".go"):
+			// testdata, embedded files and the like run the tests of the
+			// whole package they belong to
+			if dir, ok := cmd.packageDir(change.path); ok {
+				add(path.Join(module, dir), "")
+			}
+			continue
+		case strings.HasSuffix(change.path, "_test.go"):
+			// the whole package runs, the empty name stands for all tests
+			add(path.Dir(module+"/"+change.path), "")
+			continue
+		}
+
+		name, ok := names[change.path]
+		if !ok || change.added {
+			return nil, "no recorded coverage for " + change.path
+		}
+		for _, report := range files[name].Reports {
+			if !change.deleted && !touchesLines(report, change.lines) {
+				continue
+			}
+			for _, id := range index.Blocks[blockKey(name, report)] {
+				if id >= 0 && id < len(index.Tests) {
+					add(index.Tests[id].Package, index.Tests[id].Name)
+				}
+			}
+		}
+	}
+
+	result := map[string][]string{}
+	for pkg, names := range tests {
+		if names[""] {
+			result[pkg] = nil
+			continue
+		}
+		for name := range names {
+			result[pkg] = append(result[pkg], name)
+		}
+		sort.Strings(result[pkg])
+	}
+	return result, ""
+}
+
+// packageDir returns the directory of the package a file which is not Go
+// source belongs to. The files under a testdata directory belong to the
+// package next to it, and the files of a directory without any Go source do
+// not belong to any package.
+func (cmd *Cmd) packageDir(name string) (string, bool) {
+	dirs := strings.Split(path.Dir(name), "/")
+	for i, dir := range dirs {
+		if dir == "testdata" {
+			dirs = dirs[:i]
+			break
+		}
+	}
+	dir := path.Join(dirs...)
+	if dir == "" {
+		dir = "."
+	}
+	entries, err := fs.ReadDir(cmd.fsys, dir)
+	if err != nil {
+		return "", false
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".go") {
+			return dir, true
+		}
+	}
+	return "", false
+}
+
+func touchesLines(report *covReport, lines map[int]bool) bool {
+	for line := report.StartLine; line <= report.EndLine; line++ {
+		if lines[line] {
+			return true
+		}
+	}
+	return false
+}
+
+// Affected prints the go test commands running only the tests which cover
+// the code changed in the diff.
+func (cmd *Cmd) Affected(files map[string]*covFile, module string) {
+	err := cmd.affected(files, module)
+	if err != nil {
+		_, _ = fmt.Fprint(cmd.stderr, err.Error())
+		cmd.exiter.Exit(1)
+	}
+}
+
+func (cmd *Cmd) affected(files map[string]*covFile, module string) error {
+	index, err := cmd.loadTestIndex()
+	if err != nil {
+		return err
+	}
+	diff, err := cmd.readDiff()
+	if err != nil {
+		return err
+	}
+	changes, err := parseDiff(diff)
+	if err != nil {
+		return err
+	}
+
+	tests, reason := cmd.affectedTests(changes, files, module, index)
+	if reason != "" {
+		_, _ = fmt.Fprintf(cmd.stderr, "%s, running all tests\n", reason)
+		_, _ = fmt.Fprint(cmd.stdout, "go test ./...\n")
+		return nil
+	}
+	if len(tests) == 0 {
+		_, _ = fmt.Fprint(cmd.stderr, "no tests affected by the changes\n")
+		return nil
+	}
+
+	packages := make([]string, 0, len(tests))
+	for pkg := range tests {
+		packages = append(packages, pkg)
+	}
+	sort.Strings(packages)
+	for _, pkg := range packages {
+		if tests[pkg] == nil {
+			_, _ = fmt.Fprintf(cmd.stdout, "go test %s\n", pkg)
+			continue
+		}
+		_, _ = fmt.Fprintf(cmd.stdout, "go test -run '^(%s)$' %s\n", strings.Join(tests[pkg], "|"), pkg)
+	}
+	return nil
+}
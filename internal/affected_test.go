@@ -0,0 +1,210 @@
+//nolint:funlen
+package internal_test
+
+import (
+	"bytes"
+	"io/fs"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/slavsan/gocov/internal"
+)
+
+const exampleAffectedIndex = `{"tests":[` +
+	`{"package":"github.com/slavsan/gocov","name":"TestMain"},` +
+	`{"package":"github.com/slavsan/gocov/cmd","name":"TestKind"},` +
+	`{"package":"github.com/slavsan/gocov","name":"TestWork"}],` +
+	`"blocks":{"example/main.go:3.13,4.26":[0],"example/main.go:9.18,10.11":[0,2],"example/cmd/kind.go:3.25,4.9":[1]}}`
+
+func TestAffected(t *testing.T) {
+	testCases := []struct {
+		title            string
+		fsys             fs.StatFS
+		config           *internal.Config
+		expectedStdout   string
+		expectedStderr   string
+		expectedExitCode int
+	}{
+		{
+			title: "with the tests covering the changed lines",
+			fsys: fstest.MapFS{
+				"go.mod":                  {Data: []byte(`module github.com/slavsan/gocov`)},
+				"coverage.out":            {Data: []byte(exampleBranchCoverageOut)},
+				".gocov-cache/index.json": {Data: []byte(exampleAffectedIndex)},
+				"changes.diff": {Data: []byte(strings.Join([]string{
+					`diff --git a/main.go b/main.go`,
+					`--- a/main.go`,
+					`+++ b/main.go`,
+					`@@ -10,2 +10,2 @@ func work(i int) {`,
+					`-	if i < 0 {`,
+					`-		panic(i)`,
+					`+	if i < 1 {`,
+					`+		panic(i + 1)`,
+					`diff --git a/cmd/kind.go b/cmd/kind.go`,
+					`--- a/cmd/kind.go`,
+					`+++ b/cmd/kind.go`,
+					`@@ -4,0 +5 @@ func Kind(i int) string {`,
+					`+	_ = i`,
+					``,
+				}, "\n"))},
+			},
+			config: &internal.Config{
+				Color: false,
+				Patch: "changes.diff",
+			},
+			expectedStdout: strings.Join([]string{
+				`go test -run '^(TestMain|TestWork)$' github.com/slavsan/gocov`,
+				`go test -run '^(TestKind)$' github.com/slavsan/gocov/cmd`,
+				``,
+			}, "\n"),
+			expectedStderr:   "",
+			expectedExitCode: 0,
+		},
+		{
+			title: "with a changed test file and a changed non go file",
+			fsys: fstest.MapFS{
+				"go.mod":                  {Data: []byte(`module github.com/slavsan/gocov`)},
+				"coverage.out":            {Data: []byte(exampleBranchCoverageOut)},
+				".gocov-cache/index.json": {Data: []byte(exampleAffectedIndex)},
+				"changes.diff": {Data: []byte(strings.Join([]string{
+					`--- a/README.md`,
+					`+++ b/README.md`,
+					`@@ -1 +1 @@`,
+					`-# gocov`,
+					`+# GoCov`,
+					`--- a/cmd/kind_test.go`,
+					`+++ b/cmd/kind_test.go`,
+					`@@ -12 +12 @@`,
+					`-	want := "zero"`,
+					`+	want := "none"`,
+					``,
+				}, "\n"))},
+			},
+			config: &internal.Config{
+				Color: false,
+				Patch: "changes.diff",
+			},
+			expectedStdout: strings.Join([]string{
+				`go test github.com/slavsan/gocov/cmd`,
+				``,
+			}, "\n"),
+			expectedStderr:   "",
+			expectedExitCode: 0,
+		},
+		{
+			title: "with changed testdata and files outside of any package",
+			fsys: fstest.MapFS{
+				"go.mod":                  {Data: []byte(`module github.com/slavsan/gocov`)},
+				"coverage.out":            {Data: []byte(exampleBranchCoverageOut)},
+				".gocov-cache/index.json": {Data: []byte(exampleAffectedIndex)},
+				"cmd/kind.go":             {Data: []byte(exampleBranchSourceFile)},
+				"cmd/testdata/kinds.json": {Data: []byte(`["zero"]`)},
+				"docs/kinds.md":           {Data: []byte(`# Kinds`)},
+				"changes.diff": {Data: []byte(strings.Join([]string{
+					`--- a/cmd/testdata/kinds.json`,
+					`+++ b/cmd/testdata/kinds.json`,
+					`@@ -1 +1 @@`,
+					`-["zero"]`,
+					`+["none"]`,
+					`--- a/docs/kinds.md`,
+					`+++ b/docs/kinds.md`,
+					`@@ -1 +1 @@`,
+					`-# kinds`,
+					`+# Kinds`,
+					``,
+				}, "\n"))},
+			},
+			config: &internal.Config{
+				Color: false,
+				Patch: "changes.diff",
+			},
+			expectedStdout: strings.Join([]string{
+				`go test github.com/slavsan/gocov/cmd`,
+				``,
+			}, "\n"),
+			expectedStderr:   "",
+			expectedExitCode: 0,
+		},
+		{
+			title: "with a new file without recorded coverage",
+			fsys: fstest.MapFS{
+				"go.mod":                  {Data: []byte(`module github.com/slavsan/gocov`)},
+				"coverage.out":            {Data: []byte(exampleBranchCoverageOut)},
+				".gocov-cache/index.json": {Data: []byte(exampleAffectedIndex)},
+				"changes.diff": {Data: []byte(strings.Join([]string{
+					`--- /dev/null`,
+					`+++ b/cmd/new.go`,
+					`@@ -0,0 +1,3 @@`,
+					`+package cmd`,
+					`+`,
+					`+func New() {}`,
+					``,
+				}, "\n"))},
+			},
+			config: &internal.Config{
+				Color: false,
+				Patch: "changes.diff",
+			},
+			expectedStdout:   "go test ./...\n",
+			expectedStderr:   "no recorded coverage for cmd/new.go, running all tests\n",
+			expectedExitCode: 0,
+		},
+		{
+			title: "with changed lines outside of the covered blocks",
+			fsys: fstest.MapFS{
+				"go.mod":                  {Data: []byte(`module github.com/slavsan/gocov`)},
+				"coverage.out":            {Data: []byte(exampleBranchCoverageOut)},
+				".gocov-cache/index.json": {Data: []byte(exampleAffectedIndex)},
+				"changes.diff": {Data: []byte(strings.Join([]string{
+					`--- a/main.go`,
+					`+++ b/main.go`,
+					`@@ -1 +1 @@`,
+					`-package main`,
+					`+package main // the entry point`,
+					``,
+				}, "\n"))},
+			},
+			config: &internal.Config{
+				Color: false,
+				Patch: "changes.diff",
+			},
+			expectedStdout:   "",
+			expectedStderr:   "no tests affected by the changes\n",
+			expectedExitCode: 0,
+		},
+		{
+			title: "without per-test coverage",
+			fsys: fstest.MapFS{
+				"go.mod":       {Data: []byte(`module github.com/slavsan/gocov`)},
+				"coverage.out": {Data: []byte(exampleBranchCoverageOut)},
+			},
+			config: &internal.Config{
+				Color: false,
+				Patch: "changes.diff",
+			},
+			expectedStdout:   "",
+			expectedStderr:   "no per-test coverage found in .gocov-cache, run `gocov test --per-test` first",
+			expectedExitCode: 1,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.title, func(t *testing.T) {
+			var stdout bytes.Buffer
+			var stderr bytes.Buffer
+			exiter := &exiterMock{}
+			internal.NewCommand(&stdout, &stderr, tc.fsys, tc.config, exiter, &fileWriterMock{}).Exec(internal.Affected, []string{})
+			if tc.expectedStdout != stdout.String() {
+				t.Errorf("output does not match\n\texpected:\n`%s`\n\tactual:\n`%s`\n", tc.expectedStdout, stdout.String())
+			}
+			if tc.expectedStderr != stderr.String() {
+				t.Errorf("output does not match\n\texpected:\n`%s`\n\tactual:\n`%s`\n", tc.expectedStderr, stderr.String())
+			}
+			if tc.expectedExitCode != exiter.code {
+				t.Errorf("exit code does not match\n\texpected:\n`%d`\n\tactual:\n`%d`\n", tc.expectedExitCode, exiter.code)
+			}
+		})
+	}
+}
@@ -0,0 +1,184 @@
+package internal
+
+import (
+	"errors"
+	"go/ast"
+	"go/token"
+	"io/fs"
+)
+
+// branchArm is the source range of one of the arms of an if, switch or
+// select statement. The implicit arm of an if without an else or of a switch
+// without a default has no source of its own, it is made of the position of
+// the statement and its explicit arms instead.
+type branchArm struct {
+	start, end token.Position
+	stmt       token.Position
+	explicit   []branchArm
+}
+
+// branchArms returns the arms of the conditional statements in the node: the
+// bodies of if and else, the switch and type switch cases, the select cases
+// and the implicit else or default of the if and switch statements without
+// one. The operands of && and || are not counted, as the profile has no
+// blocks for them.
+func branchArms(fset *token.FileSet, node ast.Node) []branchArm {
+	var arms []branchArm
+	arm := func(n ast.Node) branchArm {
+		return branchArm{start: fset.Position(n.Pos()), end: fset.Position(n.End())}
+	}
+	implicit := func(stmt ast.Node, explicit []branchArm) {
+		arms = append(arms, explicit...)
+		arms = append(arms, branchArm{stmt: fset.Position(stmt.Pos()), explicit: explicit})
+	}
+	cases := func(stmt ast.Node, body *ast.BlockStmt) {
+		explicit := make([]branchArm, 0, len(body.List))
+		for _, c := range body.List {
+			if clause, ok := c.(*ast.CaseClause); ok && clause.List == nil {
+				// with a default every arm is explicit
+				for _, c := range body.List {
+					arms = append(arms, arm(c))
+				}
+				return
+			}
+			explicit = append(explicit, arm(c))
+		}
+		implicit(stmt, explicit)
+	}
+	ast.Inspect(node, func(n ast.Node) bool {
+		switch s := n.(type) {
+		case *ast.IfStmt:
+			switch els := s.Else.(type) {
+			case nil:
+				implicit(s, []branchArm{arm(s.Body)})
+			case *ast.BlockStmt:
+				arms = append(arms, arm(s.Body), arm(els))
+			default:
+				// an else if is counted as an if statement of its own
+				arms = append(arms, arm(s.Body))
+			}
+		case *ast.SwitchStmt:
+			cases(s, s.Body)
+		case *ast.TypeSwitchStmt:
+			cases(s, s.Body)
+		case *ast.CommClause:
+			arms = append(arms, arm(s))
+		}
+		return true
+	})
+	return arms
+}
+
+// countBranches returns the number of arms containing statements and the
+// number of them which were taken, that is with any of their blocks hit.
+// An implicit arm is taken when the statement runs more often than its
+// explicit arms, which a profile in set mode only tells when none of them
+// was taken.
+func countBranches(arms []branchArm, reports []*covReport) (taken, all int) {
+	for _, arm := range arms {
+		if arm.explicit != nil {
+			hits, ok := implicitArmHits(arm, reports)
+			if !ok {
+				continue
+			}
+			all++
+			if hits > 0 {
+				taken++
+			}
+			continue
+		}
+		var hasStatements, hit bool
+		for _, report := range reports {
+			if report.StatementsCount == 0 || !within(report, arm.start, arm.end) {
+				continue
+			}
+			hasStatements = true
+			if report.Hits > 0 {
+				hit = true
+				break
+			}
+		}
+		if !hasStatements {
+			continue
+		}
+		all++
+		if hit {
+			taken++
+		}
+	}
+	return taken, all
+}
+
+// implicitArmHits returns how many times the implicit arm was taken: the hits
+// of the block of the statement less the hits of the first block of each of
+// the explicit arms. It is not known when any of them has no block.
+func implicitArmHits(arm branchArm, reports []*covReport) (int, bool) {
+	stmt := blockAt(reports, arm.stmt)
+	if stmt == nil {
+		return 0, false
+	}
+	hits := stmt.Hits
+	for _, explicit := range arm.explicit {
+		var first *covReport
+		for _, report := range reports {
+			if report.StatementsCount == 0 || !within(report, explicit.start, explicit.end) {
+				continue
+			}
+			if first == nil || report.StartLine < first.StartLine ||
+				(report.StartLine == first.StartLine && report.StartColumn < first.StartColumn) {
+				first = report
+			}
+		}
+		if first == nil {
+			return 0, false
+		}
+		hits -= first.Hits
+	}
+	return hits, true
+}
+
+// blockAt returns the innermost block with statements holding the position.
+func blockAt(reports []*covReport, pos token.Position) *covReport {
+	var block *covReport
+	for _, report := range reports {
+		if report.StatementsCount == 0 {
+			continue
+		}
+		starts := report.StartLine < pos.Line || (report.StartLine == pos.Line && report.StartColumn <= pos.Column)
+		ends := report.EndLine > pos.Line || (report.EndLine == pos.Line && report.EndColumn > pos.Column)
+		if !starts || !ends {
+			continue
+		}
+		if block == nil || report.StartLine > block.StartLine ||
+			(report.StartLine == block.StartLine && report.StartColumn > block.StartColumn) {
+			block = report
+		}
+	}
+	return block
+}
+
+// within reports whether the block lies between the start and end positions.
+func within(report *covReport, start, end token.Position) bool {
+	afterStart := report.StartLine > start.Line ||
+		(report.StartLine == start.Line && report.StartColumn >= start.Column)
+	beforeEnd := report.EndLine < end.Line ||
+		(report.EndLine == end.Line && report.EndColumn <= end.Column)
+	return afterStart && beforeEnd
+}
+
+// calcBranches estimates the branch coverage of the files in the profile
+// from their syntax. Files which are in the profile but not in the module
+// are left without branches.
+func (cmd *Cmd) calcBranches(files map[string]*covFile, moduleDir string) error {
+	for _, target := range allTargets(files, moduleDir) {
+		fset, parsed, err := cmd.parseSource(target.path)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		target.file.BranchesTaken, target.file.Branches = countBranches(branchArms(fset, parsed), target.file.Reports)
+	}
+	return nil
+}
@@ -10,15 +10,15 @@ import (
 	"strings"
 )
 
-func (cmd *Cmd) Check(tree *Tree) {
-	err := cmd.check(tree)
+func (cmd *Cmd) Check(tree *Tree, files map[string]*covFile, moduleDir string) {
+	err := cmd.check(tree, files, moduleDir)
 	if err != nil {
-		_, _ = fmt.Fprint(cmd.stderr, err.Error(), "\n")
+		_, _ = fmt.Fprint(cmd.stderr, cmd.config.paintErr(Red), err.Error(), cmd.config.paintErr(NoColor), "\n")
 		cmd.exiter.Exit(1)
 	}
 }
 
-func (cmd *Cmd) check(tree *Tree) error {
+func (cmd *Cmd) check(tree *Tree, files map[string]*covFile, moduleDir string) error {
 	actualCoveragePercent := float64(tree.Root.covered) * 100 / float64(tree.Root.allStatements)
 	if cmd.config.File == nil {
 		return fmt.Errorf("Coverage check failed: missing .gocov file with defined threshold")
@@ -26,6 +26,27 @@ func (cmd *Cmd) check(tree *Tree) error {
 	if actualCoveragePercent < cmd.config.Threshold {
 		return fmt.Errorf("Coverage check failed: expected to have %.2f coverage, but got %.2f", cmd.config.Threshold, actualCoveragePercent)
 	}
+	if linesPercent := getLinesPercent(tree.Root); linesPercent < cmd.config.LineThreshold {
+		return fmt.Errorf("Coverage check failed: expected to have %.2f line coverage, but got %.2f", cmd.config.LineThreshold, linesPercent)
+	}
+	// code without any branches has none to miss
+	if branchPercent := getBranchPercent(tree.Root); tree.Root.branches > 0 && branchPercent < cmd.config.BranchThreshold {
+		return fmt.Errorf("Coverage check failed: expected to have %.2f branch coverage, but got %.2f", cmd.config.BranchThreshold, branchPercent)
+	}
+	if cmd.config.ExportedThreshold > 0 {
+		symbols, err := cmd.exportedSymbols(files, moduleDir)
+		if err != nil {
+			return err
+		}
+		if exported := exportedPercent(symbols); exported < cmd.config.ExportedThreshold {
+			return fmt.Errorf("Coverage check failed: expected to have %.2f exported API coverage, but got %.2f", cmd.config.ExportedThreshold, exported)
+		}
+	}
+	if cmd.config.MaxCRAP > 0 {
+		if err := cmd.checkCRAP(files, moduleDir); err != nil {
+			return err
+		}
+	}
 
 	if cmd.config.File.ReadmeThresholdRegex == "" {
 		return nil
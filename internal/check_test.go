@@ -38,6 +38,44 @@ func TestCheckCoverage(t *testing.T) {
 			expectedStderr:   "Coverage check failed: expected to have 75.52 coverage, but got 73.37\n",
 			expectedExitCode: 1,
 		},
+		{
+			title: "with coverage below threshold and colors enabled for stderr",
+			fsys: fstest.MapFS{
+				"go.mod":       {Data: []byte(`module github.com/slavsan/gospec`)},
+				"coverage.out": {Data: []byte(exampleCoverageOut)},
+				".gocov": {Data: []byte(strings.Join([]string{
+					`{`,
+					`	"threshold": 75.52`,
+					`}`,
+				}, "\n"))},
+			},
+			config: &internal.Config{
+				Color:    false,
+				ErrColor: true,
+			},
+			expectedStdout:   "",
+			expectedStderr:   internal.Red + "Coverage check failed: expected to have 75.52 coverage, but got 73.37" + internal.NoColor + "\n",
+			expectedExitCode: 1,
+		},
+		{
+			title: "with coverage below threshold and colors enabled for stdout only",
+			fsys: fstest.MapFS{
+				"go.mod":       {Data: []byte(`module github.com/slavsan/gospec`)},
+				"coverage.out": {Data: []byte(exampleCoverageOut)},
+				".gocov": {Data: []byte(strings.Join([]string{
+					`{`,
+					`	"threshold": 75.52`,
+					`}`,
+				}, "\n"))},
+			},
+			config: &internal.Config{
+				Color:    true,
+				ErrColor: false,
+			},
+			expectedStdout:   "",
+			expectedStderr:   "Coverage check failed: expected to have 75.52 coverage, but got 73.37\n",
+			expectedExitCode: 1,
+		},
 		{
 			title: "with coverage above threshold",
 			fsys: fstest.MapFS{
@@ -56,6 +94,147 @@ func TestCheckCoverage(t *testing.T) {
 			expectedStderr:   "",
 			expectedExitCode: 0,
 		},
+		{
+			title: "with line coverage below line threshold",
+			fsys: fstest.MapFS{
+				"go.mod":       {Data: []byte(`module github.com/slavsan/gocov`)},
+				"coverage.out": {Data: []byte(exampleCoverageOut6)},
+				".gocov": {Data: []byte(strings.Join([]string{
+					`{`,
+					`	"threshold": 40,`,
+					`	"line_threshold": 50`,
+					`}`,
+				}, "\n"))},
+			},
+			config: &internal.Config{
+				Color: false,
+			},
+			expectedStdout:   "",
+			expectedStderr:   "Coverage check failed: expected to have 50.00 line coverage, but got 40.91\n",
+			expectedExitCode: 1,
+		},
+		{
+			title: "with line coverage above line threshold",
+			fsys: fstest.MapFS{
+				"go.mod":       {Data: []byte(`module github.com/slavsan/gocov`)},
+				"coverage.out": {Data: []byte(exampleCoverageOut6)},
+				".gocov": {Data: []byte(strings.Join([]string{
+					`{`,
+					`	"threshold": 40,`,
+					`	"line_threshold": 40.5`,
+					`}`,
+				}, "\n"))},
+			},
+			config: &internal.Config{
+				Color: false,
+			},
+			expectedStdout:   "",
+			expectedStderr:   "",
+			expectedExitCode: 0,
+		},
+		{
+			title: "with branch coverage below branch threshold",
+			fsys: fstest.MapFS{
+				"go.mod":       {Data: []byte(`module github.com/slavsan/gocov`)},
+				"coverage.out": {Data: []byte(exampleBranchCoverageOut)},
+				"main.go":      {Data: []byte(exampleCountSourceFile)},
+				"cmd/kind.go":  {Data: []byte(exampleBranchSourceFile)},
+				".gocov": {Data: []byte(strings.Join([]string{
+					`{`,
+					`	"threshold": 70,`,
+					`	"branch_threshold": 65`,
+					`}`,
+				}, "\n"))},
+			},
+			config: &internal.Config{
+				Color: false,
+			},
+			expectedStdout:   "",
+			expectedStderr:   "Coverage check failed: expected to have 65.00 branch coverage, but got 60.00\n",
+			expectedExitCode: 1,
+		},
+		{
+			title: "with branch threshold and code without any branches",
+			fsys: fstest.MapFS{
+				"go.mod":       {Data: []byte(`module github.com/slavsan/gocov`)},
+				"coverage.out": {Data: []byte("mode: set\nexample/main.go:3.13,5.2 1 1\n")},
+				"main.go":      {Data: []byte("package main\n\nfunc main() {\n\tprintln(1)\n}\n")},
+				".gocov": {Data: []byte(strings.Join([]string{
+					`{`,
+					`	"threshold": 70,`,
+					`	"branch_threshold": 80`,
+					`}`,
+				}, "\n"))},
+			},
+			config: &internal.Config{
+				Color: false,
+			},
+			expectedStdout:   "",
+			expectedStderr:   "",
+			expectedExitCode: 0,
+		},
+		{
+			title: "with functions above the maximum CRAP score",
+			fsys: fstest.MapFS{
+				"go.mod":       {Data: []byte(`module github.com/slavsan/gocov`)},
+				"coverage.out": {Data: []byte(exampleBranchCoverageOut)},
+				"main.go":      {Data: []byte(exampleCountSourceFile)},
+				"cmd/kind.go":  {Data: []byte(exampleBranchSourceFile)},
+				".gocov": {Data: []byte(strings.Join([]string{
+					`{`,
+					`	"threshold": 70,`,
+					`	"max_crap": 2.25`,
+					`}`,
+				}, "\n"))},
+			},
+			config: &internal.Config{
+				Color: false,
+			},
+			expectedStdout:   "",
+			expectedStderr:   "Coverage check failed: expected a CRAP score of at most 2.25, but got cmd.Kind (3.14), main.work (2.50)\n",
+			expectedExitCode: 1,
+		},
+		{
+			title: "with the only function above the maximum CRAP score in an ignored file",
+			fsys: fstest.MapFS{
+				"go.mod":       {Data: []byte(`module github.com/slavsan/gocov`)},
+				"coverage.out": {Data: []byte(exampleBranchCoverageOut)},
+				"main.go":      {Data: []byte(exampleCountSourceFile)},
+				"cmd/kind.go":  {Data: []byte(exampleBranchSourceFile)},
+				".gocov": {Data: []byte(strings.Join([]string{
+					`{`,
+					`	"ignore": ["example/cmd"],`,
+					`	"max_crap": 3`,
+					`}`,
+				}, "\n"))},
+			},
+			config: &internal.Config{
+				Color: false,
+			},
+			expectedStdout:   "",
+			expectedStderr:   "",
+			expectedExitCode: 0,
+		},
+		{
+			title: "with exported API coverage below exported threshold",
+			fsys: fstest.MapFS{
+				"go.mod":        {Data: []byte(`module github.com/slavsan/gocov`)},
+				"coverage.out":  {Data: []byte(exampleExportedCoverageOut)},
+				"sdk/client.go": {Data: []byte(exampleExportedSourceFile)},
+				".gocov": {Data: []byte(strings.Join([]string{
+					`{`,
+					`	"threshold": 25,`,
+					`	"exported_threshold": 50`,
+					`}`,
+				}, "\n"))},
+			},
+			config: &internal.Config{
+				Color: false,
+			},
+			expectedStdout:   "",
+			expectedStderr:   "Coverage check failed: expected to have 50.00 exported API coverage, but got 25.00\n",
+			expectedExitCode: 1,
+		},
 		{
 			title: "with missing .gocov file",
 			fsys: fstest.MapFS{
@@ -0,0 +1,49 @@
+package internal
+
+import "fmt"
+
+const (
+	NoColor = "\033[0m"
+	Red     = "\033[0;31m"
+	Green   = "\033[0;32m"
+	Yellow  = "\033[0;33m"
+)
+
+const (
+	ColorAuto   = "auto"
+	ColorAlways = "always"
+	ColorNever  = "never"
+)
+
+// ColorEnabled is the single place deciding whether an output is colored.
+// An explicit mode always wins, otherwise colors are only used when the
+// output is a terminal and the NO_COLOR environment variable is not set.
+func ColorEnabled(mode string, noColorEnv string, isTerminal bool) (bool, error) {
+	switch mode {
+	case ColorAlways:
+		return true, nil
+	case ColorNever:
+		return false, nil
+	case "", ColorAuto:
+		return noColorEnv == "" && isTerminal, nil
+	}
+	return false, fmt.Errorf("invalid color mode: %s", mode)
+}
+
+// paint returns the escape code when colors are enabled and an empty string
+// otherwise, every colored output should go through it.
+func (c *Config) paint(code string) string {
+	if !c.Color {
+		return ""
+	}
+	return code
+}
+
+// paintErr is paint for the messages written to stderr, which is a terminal
+// or not independently of stdout.
+func (c *Config) paintErr(code string) string {
+	if !c.ErrColor {
+		return ""
+	}
+	return code
+}
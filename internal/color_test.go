@@ -0,0 +1,45 @@
+package internal_test
+
+import (
+	"testing"
+
+	"github.com/slavsan/gocov/internal"
+)
+
+func TestColorEnabled(t *testing.T) {
+	testCases := []struct {
+		title         string
+		mode          string
+		noColorEnv    string
+		isTerminal    bool
+		expected      bool
+		expectedError string
+	}{
+		{title: "auto on a terminal", mode: internal.ColorAuto, isTerminal: true, expected: true},
+		{title: "auto when piped", mode: internal.ColorAuto, isTerminal: false, expected: false},
+		{title: "auto with NO_COLOR set", mode: internal.ColorAuto, noColorEnv: "1", isTerminal: true, expected: false},
+		{title: "empty mode defaults to auto", mode: "", isTerminal: true, expected: true},
+		{title: "always when piped and NO_COLOR set", mode: internal.ColorAlways, noColorEnv: "1", expected: true},
+		{title: "never on a terminal", mode: internal.ColorNever, isTerminal: true, expected: false},
+		{title: "invalid mode", mode: "sometimes", isTerminal: true, expectedError: "invalid color mode: sometimes"},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.title, func(t *testing.T) {
+			enabled, err := internal.ColorEnabled(tc.mode, tc.noColorEnv, tc.isTerminal)
+			if tc.expectedError != "" {
+				if err == nil || err.Error() != tc.expectedError {
+					t.Errorf("error does not match\n\texpected:\n`%s`\n\tactual:\n`%v`\n", tc.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("unexpected error: %s", err.Error())
+			}
+			if tc.expected != enabled {
+				t.Errorf("color does not match\n\texpected:\n`%t`\n\tactual:\n`%t`\n", tc.expected, enabled)
+			}
+		})
+	}
+}
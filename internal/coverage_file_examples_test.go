@@ -1,5 +1,148 @@
 package internal_test
 
+const exampleCountCoverageOut = `mode: count
+example/main.go:3.13,4.26 1 1
+example/main.go:4.26,6.3 1 100
+example/main.go:9.18,10.11 1 100
+example/main.go:10.11,12.3 1 0
+`
+
+const exampleCountSourceFile = `package main
+
+func main() {
+	for i := 0; i < 100; i++ {
+		work(i)
+	}
+}
+
+func work(i int) {
+	if i < 0 {
+		panic(i)
+	}
+}
+`
+
+const exampleBranchCoverageOut = `mode: count
+example/main.go:3.13,4.26 1 1
+example/main.go:4.26,6.3 1 100
+example/main.go:9.18,10.11 1 100
+example/main.go:10.11,12.3 1 0
+example/cmd/kind.go:3.25,4.9 1 3
+example/cmd/kind.go:5.13,6.20 1 0
+example/cmd/kind.go:7.14,8.16 1 1
+example/cmd/kind.go:9.10,10.20 1 2
+`
+
+const exampleBranchSourceFile = `package cmd
+
+func Kind(n int) string {
+	switch {
+	case n < 0:
+		return "negative"
+	case n == 0:
+		return "zero"
+	default:
+		return "positive"
+	}
+}
+`
+
+const exampleImplicitArmsCoverageOut = `mode: count
+example/arms.go:4.2,4.11 1 2
+example/arms.go:5.3,6.1 1 2
+example/arms.go:7.2,7.10 1 2
+example/arms.go:11.2,11.11 1 2
+example/arms.go:12.3,13.1 1 1
+example/arms.go:13.9,13.19 1 1
+example/arms.go:14.3,15.1 1 0
+example/arms.go:16.2,16.10 1 1
+example/arms.go:20.2,20.9 1 1
+example/arms.go:22.3,22.11 1 1
+example/arms.go:24.3,24.11 1 0
+example/arms.go:26.2,26.10 1 0
+example/arms.go:30.2,30.25 1 1
+example/arms.go:31.3,31.12 1 4
+example/arms.go:32.4,33.1 1 1
+example/arms.go:35.2,35.10 1 1
+`
+
+const exampleImplicitArmsSourceFile = `package arms
+
+func If(n int) int {
+	if n > 0 {
+		n++
+	}
+	return n
+}
+
+func ElseIf(n int) int {
+	if n > 0 {
+		return 1
+	} else if n < -5 {
+		return 2
+	}
+	return 3
+}
+
+func Switch(n int) int {
+	switch {
+	case n > 0:
+		return 1
+	case n < -5:
+		return 2
+	}
+	return 3
+}
+
+func Nested(n int) int {
+	for i := 0; i < n; i++ {
+		if i > 2 {
+			n--
+		}
+	}
+	return n
+}
+`
+
+const exampleExportedCoverageOut = `mode: set
+example/sdk/client.go:5.20,7.2 1 1
+example/sdk/client.go:9.28,11.2 1 0
+example/sdk/client.go:13.29,15.2 1 0
+example/sdk/client.go:17.15,19.2 1 0
+`
+
+const exampleExportedSourceFile = `package sdk
+
+type Client struct{ n int }
+
+func New() *Client {
+	return &Client{}
+}
+
+func (c *Client) Get() int {
+	return c.n
+}
+
+func (c *Client) Put(n int) {
+	c.n = n
+}
+
+func helper() {
+	println()
+}
+
+type Options struct{}
+`
+
+const exampleCoverageOut7 = `mode: set
+github.com/slavsan/gocov/main.go:5.13,7.2 1 1
+github.com/slavsan/gocov/pkg/api/v1/internal/handlers/users.go:8.42,11.13 2 1
+github.com/slavsan/gocov/pkg/api/v1/internal/handlers/users.go:11.13,13.3 1 0
+github.com/slavsan/gocov/pkg/api/v1/internal/handlers/orders.go:8.42,11.13 2 1
+github.com/slavsan/gocov/pkg/api/v1/internal/handlers/orders.go:11.13,13.3 1 1
+github.com/slavsan/gocov/pkg/api/v1/models/order.go:3.20,5.2 1 0
+`
+
 const exampleCoverageOut5 = `mode: set
 github.com/slavsan/gocov/cmd/gocov.go:9.13,16.22 5 0
 github.com/slavsan/gocov/cmd/gocov.go:29.2,37.3 1 0
@@ -32,6 +175,48 @@ github.com/slavsan/gocov/cmd/gocov.go:20.18,22.24 2 0
 github.com/slavsan/gocov/cmd/gocov.go:22.24,24.5 1 0
 `
 
+// exampleSourceFile3 is the cmd/gocov.go file exampleCoverageOut3 was
+// generated for.
+const exampleSourceFile3 = `package cmd
+
+import (
+	"os"
+
+	"github.com/slavsan/gocov/internal"
+)
+
+func Exec() {
+	var args []string
+	config := &internal.Config{}
+	config.Color = true
+
+	command := internal.Report
+
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "check":
+			command = internal.Check
+		case "inspect":
+			command = internal.Inspect
+			if len(os.Args) > 2 {
+				args = append(args, os.Args[2])
+			}
+			//os.Args[1]
+		}
+	}
+
+	internal.Exec(
+		command,
+		args,
+		os.Stdout,
+		os.Stderr,
+		os.DirFS("."),
+		config,
+		&internal.ProcessExiter{},
+	)
+}
+`
+
 const exampleCoverageOut3 = `mode: atomic
 github.com/slavsan/gocov/cmd/gocov.go:9.13,16.22 5 0
 github.com/slavsan/gocov/cmd/gocov.go:29.2,37.3 1 0
@@ -0,0 +1,342 @@
+package internal
+
+import (
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	deadUnreferenced = "unreferenced"
+	deadUntested     = "untested"
+)
+
+// callGraph holds the type checked packages of the module and the function
+// declarations in them, keyed by the position of their name.
+type callGraph struct {
+	cmd      *Cmd
+	module   string
+	ctxt     build.Context
+	fset     *token.FileSet
+	info     *types.Info
+	fallback types.Importer
+	packages map[string]*types.Package
+	files    []*ast.File
+	decls    map[string]*ast.FuncDecl
+	keys     map[*types.Func]string
+	methods  map[string][]string
+}
+
+func (cmd *Cmd) newCallGraph(module string) *callGraph {
+	g := &callGraph{
+		cmd:    cmd,
+		module: module,
+		ctxt:   build.Default,
+		fset:   token.NewFileSet(),
+		info: &types.Info{
+			Defs: map[*ast.Ident]types.Object{},
+			Uses: map[*ast.Ident]types.Object{},
+		},
+		fallback: importer.Default(),
+		packages: map[string]*types.Package{},
+		decls:    map[string]*ast.FuncDecl{},
+		keys:     map[*types.Func]string{},
+		methods:  map[string][]string{},
+	}
+	g.ctxt.OpenFile = func(name string) (io.ReadCloser, error) {
+		return cmd.fsys.Open(filepath.ToSlash(name))
+	}
+	return g
+}
+
+// Import type checks the packages of the module from their source. Other
+// packages come from the compiler's export data, and a package which can not
+// be imported is left empty, so that the module still type checks with only
+// the references into that package unresolved.
+func (g *callGraph) Import(importPath string) (*types.Package, error) {
+	if pkg, ok := g.packages[importPath]; ok {
+		return pkg, nil
+	}
+	if importPath == g.module || strings.HasPrefix(importPath, g.module+"/") {
+		return g.load(importPath), nil
+	}
+	pkg, err := g.fallback.Import(importPath)
+	if err != nil {
+		pkg = types.NewPackage(importPath, path.Base(importPath))
+		pkg.MarkComplete()
+	}
+	g.packages[importPath] = pkg
+	return pkg, nil
+}
+
+// load parses and type checks a package of the module, leaving out the test
+// files and the files excluded by build constraints.
+func (g *callGraph) load(importPath string) *types.Package {
+	dir := strings.TrimPrefix(strings.TrimPrefix(importPath, g.module), "/")
+	if dir == "" {
+		dir = "."
+	}
+	pkg := types.NewPackage(importPath, path.Base(importPath))
+	g.packages[importPath] = pkg
+
+	entries, _ := fs.ReadDir(g.cmd.fsys, dir)
+	var files []*ast.File
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		if match, err := g.ctxt.MatchFile(dir, name); err != nil || !match {
+			continue
+		}
+		src, err := fs.ReadFile(g.cmd.fsys, path.Join(dir, name))
+		if err != nil {
+			continue
+		}
+		parsed, err := parser.ParseFile(g.fset, path.Join(dir, name), src, 0)
+		if err != nil {
+			continue
+		}
+		files = append(files, parsed)
+	}
+	if len(files) == 0 {
+		return pkg
+	}
+
+	conf := types.Config{Importer: g, Error: func(error) {}}
+	checked, _ := conf.Check(importPath, g.fset, files, g.info)
+	if checked != nil {
+		pkg = checked
+		g.packages[importPath] = pkg
+	}
+	for _, file := range files {
+		g.addDecls(file)
+	}
+	g.files = append(g.files, files...)
+	return pkg
+}
+
+func (g *callGraph) addDecls(file *ast.File) {
+	for _, decl := range file.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok {
+			continue
+		}
+		key := g.fset.Position(fn.Name.Pos()).String()
+		g.decls[key] = fn
+		if obj, ok := g.info.Defs[fn.Name].(*types.Func); ok {
+			g.keys[obj] = key
+		}
+		if fn.Recv != nil {
+			g.methods[fn.Name.Name] = append(g.methods[fn.Name.Name], key)
+		}
+	}
+}
+
+// loadModule type checks every package of the module.
+func (g *callGraph) loadModule() error {
+	return fs.WalkDir(g.cmd.fsys, ".", func(name string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !entry.IsDir() {
+			return nil
+		}
+		base := path.Base(name)
+		if name != "." && (strings.HasPrefix(base, ".") || strings.HasPrefix(base, "_") || base == "testdata" || base == "vendor") {
+			return fs.SkipDir
+		}
+		importPath := g.module
+		if name != "." {
+			importPath += "/" + name
+		}
+		_, _ = g.Import(importPath)
+		return nil
+	})
+}
+
+// isRoot tells whether the function can be called from outside of the
+// call graph: main and init, the exported functions of the packages other
+// modules can import and the exported methods, which interfaces or
+// reflection can call.
+func (g *callGraph) isRoot(file *ast.File, fn *ast.FuncDecl) bool {
+	if fn.Recv != nil {
+		return fn.Name.IsExported()
+	}
+	switch {
+	case fn.Name.Name == "init":
+		return true
+	case file.Name.Name == "main":
+		return fn.Name.Name == "main"
+	case !fn.Name.IsExported():
+		return false
+	}
+	dir := g.fset.Position(file.Pos()).Filename
+	for _, elem := range strings.Split(path.Dir(dir), "/") {
+		if elem == "internal" {
+			return false
+		}
+	}
+	return true
+}
+
+// reachable returns the keys of the functions reachable from the roots and
+// from the package level declarations. A call of an interface method
+// reaches all of the methods with its name.
+func (g *callGraph) reachable() map[string]bool {
+	var (
+		seen       = map[string]bool{}
+		interfaces = map[string]bool{}
+		queue      []string
+	)
+	enqueue := func(key string) {
+		if !seen[key] {
+			seen[key] = true
+			queue = append(queue, key)
+		}
+	}
+	references := func(node ast.Node) {
+		ast.Inspect(node, func(n ast.Node) bool {
+			ident, ok := n.(*ast.Ident)
+			if !ok {
+				return true
+			}
+			fn, ok := g.info.Uses[ident].(*types.Func)
+			if !ok {
+				return true
+			}
+			if recv := fn.Type().(*types.Signature).Recv(); recv != nil && types.IsInterface(recv.Type()) {
+				if !interfaces[fn.Name()] {
+					interfaces[fn.Name()] = true
+					for _, key := range g.methods[fn.Name()] {
+						enqueue(key)
+					}
+				}
+				return true
+			}
+			if key, ok := g.keys[fn.Origin()]; ok {
+				enqueue(key)
+			}
+			return true
+		})
+	}
+
+	for _, file := range g.files {
+		for _, decl := range file.Decls {
+			switch d := decl.(type) {
+			case *ast.GenDecl:
+				references(d)
+			case *ast.FuncDecl:
+				if g.isRoot(file, d) {
+					enqueue(g.fset.Position(d.Name.Pos()).String())
+				}
+			}
+		}
+	}
+	for len(queue) > 0 {
+		key := queue[0]
+		queue = queue[1:]
+		if fn := g.decls[key]; fn.Body != nil {
+			references(fn.Body)
+		}
+	}
+	return seen
+}
+
+// deadFunc is a function without any covered statement.
+type deadFunc struct {
+	fn     *funcInfo
+	all    int
+	status string
+}
+
+// deadFuncs returns the uncovered functions of the profile, each tagged as
+// unreferenced when nothing outside of the tests reaches it, or untested.
+func (cmd *Cmd) deadFuncs(files map[string]*covFile, moduleDir string) ([]*deadFunc, error) {
+	funcs, err := cmd.collectFuncs(cmd.reportTargets(files, moduleDir))
+	if err != nil {
+		return nil, err
+	}
+	module, err := getModule(cmd.fsys)
+	if err != nil {
+		return nil, err
+	}
+	graph := cmd.newCallGraph(module)
+	if err = graph.loadModule(); err != nil {
+		return nil, fmt.Errorf("failed to load the module: %w", err)
+	}
+	reachable := graph.reachable()
+
+	var dead []*deadFunc
+	for _, fn := range funcs {
+		covered, all := fn.coverage()
+		if covered > 0 || all == 0 {
+			continue
+		}
+		key := fn.fset.Position(fn.decl.Name.Pos()).String()
+		status := deadUntested
+		if _, ok := graph.decls[key]; ok && !reachable[key] {
+			status = deadUnreferenced
+		}
+		dead = append(dead, &deadFunc{fn: fn, all: all, status: status})
+	}
+	return dead, nil
+}
+
+// ReportDead lists the uncovered functions and whether they are dead code,
+// to be deleted, or code which is missing tests.
+func (cmd *Cmd) ReportDead(files map[string]*covFile, moduleDir string) {
+	dead, err := cmd.deadFuncs(files, moduleDir)
+	if err != nil {
+		_, _ = fmt.Fprint(cmd.stderr, err.Error())
+		cmd.exiter.Exit(1)
+		return
+	}
+	if len(dead) == 0 {
+		_, _ = fmt.Fprint(cmd.stdout, "no uncovered functions\n")
+		return
+	}
+
+	columns := []*column{
+		{title: "Function", width: len("Function"), minWidth: len("Function")},
+		{title: "Location", width: len("Location"), minWidth: len("Location")},
+		{title: "Stmts", width: len("Stmts"), right: true},
+		{title: "Status", width: len(deadUnreferenced)},
+	}
+	rows := make([][]string, 0, len(dead))
+	for _, d := range dead {
+		row := []string{
+			d.fn.String(),
+			fmt.Sprintf("%s:%d", d.fn.target.path, d.fn.start.Line),
+			fmt.Sprintf("%d", d.all),
+			d.status,
+		}
+		for i, cell := range row {
+			if len(cell) > columns[i].width {
+				columns[i].width = len(cell)
+			}
+		}
+		rows = append(rows, row)
+	}
+
+	tbl := newTable(cmd.stdout, cmd.config.Style, columns)
+	tbl.fit(cmd.config.Width)
+	tbl.header()
+	for i, row := range rows {
+		color := Red
+		if dead[i].status == deadUnreferenced {
+			color = Yellow
+		}
+		tbl.row(row, cmd.config.paint(color), cmd.config.paint(NoColor))
+	}
+	tbl.footer()
+}
@@ -0,0 +1,215 @@
+//nolint:funlen
+package internal_test
+
+import (
+	"bytes"
+	"io/fs"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/slavsan/gocov/internal"
+)
+
+const exampleDeadCoverageOut = `mode: set
+example/main.go:5.13,7.2 1 1
+example/cmd/run.go:16.28,18.2 1 1
+example/cmd/run.go:20.33,22.2 1 0
+example/cmd/run.go:24.30,26.2 1 0
+example/cmd/run.go:28.21,29.11 1 1
+example/cmd/run.go:29.11,31.3 1 0
+example/cmd/run.go:32.2,33.57 2 1
+example/cmd/run.go:36.24,39.2 2 0
+example/cmd/run.go:41.19,43.2 1 0
+example/cmd/run.go:45.19,47.2 1 0
+example/cmd/run.go:49.19,51.2 1 0
+example/cmd/run.go:53.21,55.2 1 0
+example/internal/util.go:3.25,5.2 1 1
+example/internal/util.go:7.19,9.2 1 0
+`
+
+const exampleDeadSourceFile = `package cmd
+
+import (
+	"strings"
+
+	"github.com/slavsan/gocov/internal"
+)
+
+type shape interface {
+	area() int
+	perimeter() int
+}
+
+type square struct{ side int }
+
+func (s square) area() int {
+	return s.side * s.side
+}
+
+func (s square) perimeter() int {
+	return 4 * s.side
+}
+
+func (s square) diagonal() int {
+	return s.side * 141 / 100
+}
+
+func Run(n int) int {
+	if n > 1 {
+		return helper(square{side: n})
+	}
+	var s shape = square{side: n}
+	return s.area() + internal.Used(strings.Repeat("a", n))
+}
+
+func helper(s shape) int {
+	total := s.perimeter()
+	return total
+}
+
+func orphan() int {
+	return 0
+}
+
+func Unused() int {
+	return orphan()
+}
+
+func lonely() int {
+	return lonelier()
+}
+
+func lonelier() int {
+	return 1
+}
+`
+
+var exampleDeadFS = fstest.MapFS{
+	"go.mod":       {Data: []byte(`module github.com/slavsan/gocov`)},
+	"coverage.out": {Data: []byte(exampleDeadCoverageOut)},
+	"main.go": {Data: []byte(strings.Join([]string{
+		`package main`,
+		``,
+		`import "github.com/slavsan/gocov/cmd"`,
+		``,
+		`func main() {`,
+		`	cmd.Run(1)`,
+		`}`,
+		``,
+	}, "\n"))},
+	"cmd/run.go": {Data: []byte(exampleDeadSourceFile)},
+	"cmd/run_test.go": {Data: []byte(strings.Join([]string{
+		`package cmd`,
+		``,
+		`func useLonely() int { return lonely() }`,
+		``,
+	}, "\n"))},
+	"internal/util.go": {Data: []byte(strings.Join([]string{
+		`package internal`,
+		``,
+		`func Used(s string) int {`,
+		`	return len(s)`,
+		`}`,
+		``,
+		`func Unused() int {`,
+		`	return 0`,
+		`}`,
+		``,
+	}, "\n"))},
+}
+
+func TestReportDead(t *testing.T) {
+	testCases := []struct {
+		title            string
+		fsys             fs.StatFS
+		config           *internal.Config
+		expectedStdout   string
+		expectedStderr   string
+		expectedExitCode int
+	}{
+		{
+			title: "with unreferenced and untested functions",
+			fsys:  exampleDeadFS,
+			config: &internal.Config{
+				Color: false,
+				Dead:  true,
+			},
+			expectedStdout: strings.Join([]string{
+				`|----------------------|--------------------|-------|--------------|`,
+				`| Function             | Location           | Stmts | Status       |`,
+				`|----------------------|--------------------|-------|--------------|`,
+				`| cmd.square.perimeter | cmd/run.go:20      |     1 | untested     |`,
+				`| cmd.square.diagonal  | cmd/run.go:24      |     1 | unreferenced |`,
+				`| cmd.helper           | cmd/run.go:36      |     2 | untested     |`,
+				`| cmd.orphan           | cmd/run.go:41      |     1 | untested     |`,
+				`| cmd.Unused           | cmd/run.go:45      |     1 | untested     |`,
+				`| cmd.lonely           | cmd/run.go:49      |     1 | unreferenced |`,
+				`| cmd.lonelier         | cmd/run.go:53      |     1 | unreferenced |`,
+				`| internal.Unused      | internal/util.go:7 |     1 | unreferenced |`,
+				`|----------------------|--------------------|-------|--------------|`,
+				``,
+			}, "\n"),
+			expectedStderr:   "",
+			expectedExitCode: 0,
+		},
+		{
+			title: "with the functions of ignored files left out",
+			fsys:  withFile(exampleDeadFS, ".gocov", `{"ignore": ["example/internal"]}`),
+			config: &internal.Config{
+				Color: false,
+				Dead:  true,
+			},
+			expectedStdout: strings.Join([]string{
+				`|----------------------|---------------|-------|--------------|`,
+				`| Function             | Location      | Stmts | Status       |`,
+				`|----------------------|---------------|-------|--------------|`,
+				`| cmd.square.perimeter | cmd/run.go:20 |     1 | untested     |`,
+				`| cmd.square.diagonal  | cmd/run.go:24 |     1 | unreferenced |`,
+				`| cmd.helper           | cmd/run.go:36 |     2 | untested     |`,
+				`| cmd.orphan           | cmd/run.go:41 |     1 | untested     |`,
+				`| cmd.Unused           | cmd/run.go:45 |     1 | untested     |`,
+				`| cmd.lonely           | cmd/run.go:49 |     1 | unreferenced |`,
+				`| cmd.lonelier         | cmd/run.go:53 |     1 | unreferenced |`,
+				`|----------------------|---------------|-------|--------------|`,
+				``,
+			}, "\n"),
+			expectedStderr:   "",
+			expectedExitCode: 0,
+		},
+		{
+			title: "without uncovered functions",
+			fsys: fstest.MapFS{
+				"go.mod":       {Data: []byte(`module github.com/slavsan/gocov`)},
+				"coverage.out": {Data: []byte(exampleCountCoverageOut)},
+				"main.go":      {Data: []byte(exampleCountSourceFile)},
+			},
+			config: &internal.Config{
+				Color: false,
+				Dead:  true,
+			},
+			expectedStdout:   "no uncovered functions\n",
+			expectedStderr:   "",
+			expectedExitCode: 0,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.title, func(t *testing.T) {
+			var stdout bytes.Buffer
+			var stderr bytes.Buffer
+			exiter := &exiterMock{}
+			internal.NewCommand(&stdout, &stderr, tc.fsys, tc.config, exiter, &fileWriterMock{}).Exec(internal.Report, []string{})
+			if tc.expectedStdout != stdout.String() {
+				t.Errorf("table does not match\n\texpected:\n`%s`\n\tactual:\n`%s`\n", tc.expectedStdout, stdout.String())
+			}
+			if tc.expectedStderr != stderr.String() {
+				t.Errorf("table does not match\n\texpected:\n`%s`\n\tactual:\n`%s`\n", tc.expectedStderr, stderr.String())
+			}
+			if tc.expectedExitCode != exiter.code {
+				t.Errorf("exit code does not match\n\texpected:\n`%d`\n\tactual:\n`%d`\n", tc.expectedExitCode, exiter.code)
+			}
+		})
+	}
+}
@@ -0,0 +1,210 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+)
+
+// exportedSymbol is an exported function, method or type of a package. A
+// type is tested when any of its methods is.
+type exportedSymbol struct {
+	name       string
+	pkg        string
+	target     *inspectTarget
+	line       int
+	statements int
+	hit        bool
+}
+
+type exportedPackage struct {
+	name    string
+	symbols []*exportedSymbol
+	tested  int
+}
+
+// exportedSymbols returns the exported symbols of the files in the profile
+// which contain statements, symbols without any can not be tested.
+func (cmd *Cmd) exportedSymbols(files map[string]*covFile, moduleDir string) ([]*exportedSymbol, error) {
+	var (
+		symbols []*exportedSymbol
+		methods = map[*inspectTarget][]*funcInfo{}
+		targets = cmd.reportTargets(files, moduleDir)
+	)
+	for _, target := range targets {
+		fset, parsed, err := cmd.parseSource(target.path)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		funcs := fileFuncs(fset, parsed, target)
+		symbols = append(symbols, fileExportedSymbols(fset, parsed, funcs, target, moduleDir)...)
+		methods[target] = funcs
+	}
+
+	// methods can be declared in other files than their type
+	byName := map[string]*exportedSymbol{}
+	for _, symbol := range symbols {
+		byName[symbol.pkg+" "+symbol.name] = symbol
+	}
+	for _, target := range targets {
+		for _, fn := range methods[target] {
+			typ, ok := byName[exportedPackageName(target, moduleDir)+" "+fn.pkg+"."+fn.recv]
+			if fn.recv == "" || !ok {
+				continue
+			}
+			covered, all := fn.coverage()
+			typ.statements += all
+			typ.hit = typ.hit || covered > 0
+		}
+	}
+
+	var result []*exportedSymbol
+	for _, symbol := range symbols {
+		if symbol.statements > 0 {
+			result = append(result, symbol)
+		}
+	}
+	return result, nil
+}
+
+func fileExportedSymbols(fset *token.FileSet, parsed *ast.File, funcs []*funcInfo, target *inspectTarget, moduleDir string) []*exportedSymbol {
+	var symbols []*exportedSymbol
+	pkg := exportedPackageName(target, moduleDir)
+	for _, decl := range parsed.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			typ, ok := spec.(*ast.TypeSpec)
+			if !ok || !typ.Name.IsExported() {
+				continue
+			}
+			symbols = append(symbols, &exportedSymbol{
+				name:   parsed.Name.Name + "." + typ.Name.Name,
+				pkg:    pkg,
+				target: target,
+				line:   fset.Position(typ.Pos()).Line,
+			})
+		}
+	}
+	for _, fn := range funcs {
+		if !ast.IsExported(fn.name) || (fn.recv != "" && !ast.IsExported(fn.recv)) {
+			continue
+		}
+		covered, all := fn.coverage()
+		symbols = append(symbols, &exportedSymbol{
+			name:       fn.String(),
+			pkg:        pkg,
+			target:     target,
+			line:       fn.start.Line,
+			statements: all,
+			hit:        covered > 0,
+		})
+	}
+	return symbols
+}
+
+// exportedPackageName is the package directory relative to the module's
+// parent directory, as shown in the report.
+func exportedPackageName(target *inspectTarget, moduleDir string) string {
+	return strings.TrimPrefix(path.Dir(target.file.Name), moduleDir+"/")
+}
+
+func groupExported(symbols []*exportedSymbol) []*exportedPackage {
+	byName := map[string]*exportedPackage{}
+	var packages []*exportedPackage
+	for _, symbol := range symbols {
+		pkg, ok := byName[symbol.pkg]
+		if !ok {
+			pkg = &exportedPackage{name: symbol.pkg}
+			byName[symbol.pkg] = pkg
+			packages = append(packages, pkg)
+		}
+		pkg.symbols = append(pkg.symbols, symbol)
+		if symbol.hit {
+			pkg.tested++
+		}
+	}
+	sort.Slice(packages, func(i, j int) bool { return packages[i].name < packages[j].name })
+	return packages
+}
+
+func exportedPercent(symbols []*exportedSymbol) float64 {
+	var tested int
+	for _, symbol := range symbols {
+		if symbol.hit {
+			tested++
+		}
+	}
+	return percent(tested, len(symbols))
+}
+
+// ReportExported prints the share of tested exported symbols per package,
+// followed by the list of the untested ones.
+func (cmd *Cmd) ReportExported(files map[string]*covFile, moduleDir string) {
+	symbols, err := cmd.exportedSymbols(files, moduleDir)
+	if err != nil {
+		_, _ = fmt.Fprint(cmd.stderr, err.Error())
+		cmd.exiter.Exit(1)
+		return
+	}
+
+	columns := []*column{
+		{title: "Package", width: len("Package"), minWidth: len("Package")},
+		{title: "Exported", width: len("Exported"), right: true},
+		{title: "% Exported", width: len("% Exported"), right: true},
+	}
+	packages := groupExported(symbols)
+	rows := make([][]string, 0, len(packages))
+	for _, pkg := range packages {
+		row := []string{
+			pkg.name,
+			fmt.Sprintf("%d/%d", pkg.tested, len(pkg.symbols)),
+			fmt.Sprintf("%7.2f%%", percent(pkg.tested, len(pkg.symbols))),
+		}
+		for i, cell := range row {
+			if len(cell) > columns[i].width {
+				columns[i].width = len(cell)
+			}
+		}
+		rows = append(rows, row)
+	}
+
+	tbl := newTable(cmd.stdout, cmd.config.Style, columns)
+	tbl.fit(cmd.config.Width)
+	tbl.header()
+	for i, row := range rows {
+		color, noColorValue := getColor(percent(packages[i].tested, len(packages[i].symbols)), cmd.config.Watermarks)
+		tbl.row(row, cmd.config.paint(color), cmd.config.paint(noColorValue))
+	}
+	tbl.footer()
+
+	var untested []*exportedSymbol
+	for _, symbol := range symbols {
+		if !symbol.hit {
+			untested = append(untested, symbol)
+		}
+	}
+	if len(untested) == 0 {
+		return
+	}
+	sort.SliceStable(untested, func(i, j int) bool {
+		if untested[i].target.path != untested[j].target.path {
+			return untested[i].target.path < untested[j].target.path
+		}
+		return untested[i].line < untested[j].line
+	})
+	_, _ = fmt.Fprint(cmd.stdout, "\nUntested exported symbols:\n")
+	for _, symbol := range untested {
+		_, _ = fmt.Fprintf(cmd.stdout, "  %s:%d %s\n", symbol.target.path, symbol.line, symbol.name)
+	}
+}
@@ -3,6 +3,7 @@ package internal
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 )
 
 type FileWriterInterface interface {
@@ -15,9 +16,12 @@ type FileWriter struct {
 	f *os.File
 }
 
-func (fw *FileWriter) Open(filepath string) error {
-	var err error
-	fw.f, err = os.OpenFile(filepath, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0755) //nolint:gofumpt
+func (fw *FileWriter) Open(path string) error {
+	err := os.MkdirAll(filepath.Dir(path), 0o755)
+	if err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+	fw.f, err = os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0755) //nolint:gofumpt
 	if err != nil {
 		return fmt.Errorf("failed to open file: %w", err)
 	}
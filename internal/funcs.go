@@ -0,0 +1,278 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+)
+
+// funcInfo is a function or method declaration found in one of the files of
+// the coverage profile.
+type funcInfo struct {
+	pkg     string
+	recv    string
+	pointer bool
+	name    string
+	target  *inspectTarget
+	fset    *token.FileSet
+	decl    *ast.FuncDecl
+	start   token.Position
+	end     token.Position
+}
+
+// String returns the name of the function in the format used by pprof,
+// e.g. internal.(*Cmd).check.
+func (f *funcInfo) String() string {
+	switch {
+	case f.recv == "":
+		return f.pkg + "." + f.name
+	case f.pointer:
+		return f.pkg + ".(*" + f.recv + ")." + f.name
+	default:
+		return f.pkg + "." + f.recv + "." + f.name
+	}
+}
+
+// matches reports whether the selector names the function. The selector can
+// be just the name, Recv.name, pkg.name, pkg.Recv.name or any of them with
+// the full import path of the package, the receiver can be wrapped in (*T)
+// as well.
+func (f *funcInfo) matches(selector string) bool {
+	selector = strings.NewReplacer("(*", "", "(", "", ")", "").Replace(selector)
+	importPath := path.Dir(f.target.file.Name)
+	candidates := []string{f.name}
+	if f.recv != "" {
+		candidates = append(candidates, f.recv+"."+f.name)
+	}
+	qualified := []string{f.pkg + "." + candidates[len(candidates)-1], importPath + "." + candidates[len(candidates)-1]}
+	for _, candidate := range append(candidates, qualified...) {
+		if selector == candidate {
+			return true
+		}
+	}
+	return strings.HasSuffix(qualified[1], "/"+selector)
+}
+
+// contains reports whether the block is within the function.
+func (f *funcInfo) contains(report *covReport) bool {
+	return within(report, f.start, f.end)
+}
+
+// coverage returns the number of covered and all statements in the function.
+func (f *funcInfo) coverage() (covered, all int) {
+	for _, report := range f.target.file.Reports {
+		if !f.contains(report) {
+			continue
+		}
+		all += report.StatementsCount
+		if report.Hits > 0 {
+			covered += report.StatementsCount
+		}
+	}
+	return covered, all
+}
+
+// branches returns the number of taken and all branch arms in the function.
+func (f *funcInfo) branches() (taken, all int) {
+	return countBranches(branchArms(f.fset, f.decl), f.target.file.Reports)
+}
+
+// isFuncSelector reports whether an inspect argument names a function, as in
+// internal.(*Cmd).check, rather than a file.
+func isFuncSelector(arg string) bool {
+	return strings.Contains(arg, ".(")
+}
+
+// parseSource reads and parses the source file at the module relative path.
+func (cmd *Cmd) parseSource(targetFile string) (*token.FileSet, *ast.File, error) {
+	f, err := cmd.fsys.Open(targetFile)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open file to inspect: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read target file to inspect: %w", err)
+	}
+
+	fset := token.NewFileSet()
+	parsed, err := parser.ParseFile(fset, targetFile, data, 0)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse %s: %w", targetFile, err)
+	}
+	return fset, parsed, nil
+}
+
+// parseFuncs returns the function declarations in the target file.
+func (cmd *Cmd) parseFuncs(target *inspectTarget) ([]*funcInfo, error) {
+	fset, parsed, err := cmd.parseSource(target.path)
+	if err != nil {
+		return nil, err
+	}
+	return fileFuncs(fset, parsed, target), nil
+}
+
+func fileFuncs(fset *token.FileSet, parsed *ast.File, target *inspectTarget) []*funcInfo {
+	var funcs []*funcInfo
+	for _, decl := range parsed.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok {
+			continue
+		}
+		info := &funcInfo{
+			pkg:    parsed.Name.Name,
+			name:   fn.Name.Name,
+			target: target,
+			fset:   fset,
+			decl:   fn,
+			start:  fset.Position(fn.Pos()),
+			end:    fset.Position(fn.End()),
+		}
+		if fn.Recv != nil && len(fn.Recv.List) > 0 {
+			info.recv, info.pointer = receiverName(fn.Recv.List[0].Type)
+		}
+		funcs = append(funcs, info)
+	}
+	return funcs
+}
+
+func receiverName(expr ast.Expr) (string, bool) {
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		name, _ := receiverName(t.X)
+		return name, true
+	case *ast.IndexExpr:
+		return receiverName(t.X)
+	case *ast.IndexListExpr:
+		return receiverName(t.X)
+	case *ast.ParenExpr:
+		return receiverName(t.X)
+	case *ast.Ident:
+		return t.Name, false
+	}
+	return "", false
+}
+
+// allTargets returns the targets for all of the files in the profile,
+// sorted by name.
+func allTargets(files map[string]*covFile, moduleDir string) []*inspectTarget {
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	targets := make([]*inspectTarget, 0, len(names))
+	for _, name := range names {
+		targets = append(targets, newInspectTarget(name, files, moduleDir))
+	}
+	return targets
+}
+
+// reportTargets returns the targets of the files in the profile, leaving out
+// the ones ignored by the .gocov file like the report does.
+func (cmd *Cmd) reportTargets(files map[string]*covFile, moduleDir string) []*inspectTarget {
+	var targets []*inspectTarget
+	for _, target := range allTargets(files, moduleDir) {
+		if !isIgnored(target.file, cmd.config.File) {
+			targets = append(targets, target)
+		}
+	}
+	return targets
+}
+
+// collectFuncs returns the functions declared in the targets. Files which are
+// in the profile but not in the module are skipped.
+func (cmd *Cmd) collectFuncs(targets []*inspectTarget) ([]*funcInfo, error) {
+	var funcs []*funcInfo
+	for _, target := range targets {
+		found, err := cmd.parseFuncs(target)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		funcs = append(funcs, found...)
+	}
+	return funcs, nil
+}
+
+// findFuncs returns the functions matching any of the selectors, searching
+// the given targets or, when there are none, all of the files in the
+// profile.
+func (cmd *Cmd) findFuncs(selectors []string, targets []*inspectTarget, files map[string]*covFile, moduleDir string) ([]*funcInfo, error) {
+	if len(targets) == 0 {
+		targets = allTargets(files, moduleDir)
+	}
+	funcs, err := cmd.collectFuncs(targets)
+	if err != nil {
+		return nil, err
+	}
+
+	var matches []*funcInfo
+	for _, fn := range funcs {
+		for _, selector := range selectors {
+			if fn.matches(selector) {
+				matches = append(matches, fn)
+				break
+			}
+		}
+	}
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("no function found for the given name: %s", strings.Join(selectors, ", "))
+	}
+	return matches, nil
+}
+
+// inspectFuncs renders the source of the functions matching the selectors
+// with their own coverage.
+func (cmd *Cmd) inspectFuncs(selectors []string, args []string, files map[string]*covFile, moduleDir string) (string, error) {
+	var targets []*inspectTarget
+	if len(args) > 0 {
+		var err error
+		targets, err = cmd.findTargets(args, files, moduleDir)
+		if err != nil {
+			return "", err
+		}
+	}
+	funcs, err := cmd.findFuncs(selectors, targets, files, moduleDir)
+	if err != nil {
+		return "", err
+	}
+
+	var sb strings.Builder
+	for _, fn := range funcs {
+		lines, err := cmd.sourceLines(fn.target.file, fn.target.path)
+		if err != nil {
+			return "", err
+		}
+		covered, all := fn.coverage()
+		taken, branches := fn.branches()
+		_, _ = fmt.Fprintf(&sb, "func %s in %s: %d/%d statements covered (%.2f%%), %d/%d branches taken\n",
+			fn, fn.target.path, covered, all, percent(covered, all), taken, branches,
+		)
+		g, err := cmd.gutterFor(fn.target.file, lines, fn.end.Line+1)
+		if err != nil {
+			return "", err
+		}
+		for num := fn.start.Line - 1; num < fn.end.Line && num < len(lines); num++ {
+			g.write(&sb, num, lines[num])
+		}
+	}
+	return sb.String(), nil
+}
+
+func percent(covered, all int) float64 {
+	if all == 0 {
+		return 0
+	}
+	return float64(covered) * 100 / float64(all)
+}
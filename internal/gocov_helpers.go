@@ -27,35 +27,143 @@ const (
 	Inspect
 	Test
 	ConfigFile
-)
-
-const (
-	NoColor = "\033[0m"
-	Red     = "\033[0;31m"
-	Green   = "\033[0;32m"
-	Yellow  = "\033[0;33m"
+	Hotspots
+	Affected
+	Stability
+	Mutate
 )
 
 type Config struct {
-	Color        bool
-	Depth        int
-	WithFullPath bool
-	ExactPath    bool
-	Threshold    float64
-	File         *GocovConfig
-	Global       *GocovConfig
-	ReportFile   string
-	HTMLOutput   bool
+	Color             bool
+	ErrColor          bool
+	Depth             int
+	WithFullPath      bool
+	ExactPath         bool
+	Threshold         float64
+	File              *GocovConfig
+	Global            *GocovConfig
+	ReportFile        string
+	HTMLOutput        bool
+	SortBy            string
+	Reverse           bool
+	MinPercent        *float64
+	MaxPercent        *float64
+	OnlyUncovered     bool
+	Top               int
+	Flat              bool
+	Compact           bool
+	Style             string
+	Width             int
+	BarWidth          int
+	BarSymbol         string
+	Watermarks        Watermarks
+	UncoveredOnly     bool
+	Context           int
+	ListFiles         bool
+	Counts            bool
+	Heat              bool
+	Func              string
+	Highlight         bool
+	MarkCovered       bool
+	MarkUncovered     bool
+	UncoveredLines    bool
+	Lines             bool
+	LineThreshold     float64
+	Branches          bool
+	BranchThreshold   float64
+	MaxCRAP           float64
+	Exported          bool
+	ExportedThreshold float64
+	PerTest           bool
+	CacheDir          string
+	Tests             bool
+	Diff              string
+	Patch             string
+	Runs              int
+	ListMutants       bool
+	Timeout           string
+	Dead              bool
+	HTMLDir           string
+	Output            string
+	Theme             string
+	HTML              HTMLConfig
 }
 
-func (c *Config) Update() {
+func (c *Config) Update() error {
 	c.updateThreshold()
+	if err := c.updateWatermarks(); err != nil {
+		return err
+	}
 	if c.ReportFile == "" {
 		c.ReportFile = "coverage.out"
 	}
+	// the output of the config files is where the html report goes, the
+	// text reports are written to stdout unless --output is given
+	if c.Output == "" && c.HTMLOutput {
+		if c.File != nil && c.File.Output != "" {
+			c.Output = c.File.Output
+		} else if c.Global != nil && c.Global.Output != "" {
+			c.Output = c.Global.Output
+		}
+	}
+	c.updateHTML()
+	return nil
+}
+
+// updateHTML fills in the html options which are not set yet, from the
+// .gocov file of the module first and then from the global one.
+func (c *Config) updateHTML() {
+	for _, conf := range []*GocovConfig{c.File, c.Global} {
+		if conf == nil || conf.HTML == nil {
+			continue
+		}
+		if c.HTML.Template == "" {
+			c.HTML.Template = conf.HTML.Template
+		}
+		if c.HTML.Title == "" {
+			c.HTML.Title = conf.HTML.Title
+		}
+		if c.HTML.CSS == "" {
+			c.HTML.CSS = conf.HTML.CSS
+		}
+		if c.Theme == "" {
+			c.Theme = conf.HTML.Theme
+		}
+	}
+	if c.Theme == "" {
+		c.Theme = ThemeLight
+	}
 }
 
 func (c *Config) updateThreshold() {
+	if c.LineThreshold == 0 {
+		if c.File != nil && c.File.LineThreshold != 0 {
+			c.LineThreshold = c.File.LineThreshold
+		} else if c.Global != nil && c.Global.LineThreshold != 0 {
+			c.LineThreshold = c.Global.LineThreshold
+		}
+	}
+	if c.ExportedThreshold == 0 {
+		if c.File != nil && c.File.ExportedThreshold != 0 {
+			c.ExportedThreshold = c.File.ExportedThreshold
+		} else if c.Global != nil && c.Global.ExportedThreshold != 0 {
+			c.ExportedThreshold = c.Global.ExportedThreshold
+		}
+	}
+	if c.MaxCRAP == 0 {
+		if c.File != nil && c.File.MaxCRAP != 0 {
+			c.MaxCRAP = c.File.MaxCRAP
+		} else if c.Global != nil && c.Global.MaxCRAP != 0 {
+			c.MaxCRAP = c.Global.MaxCRAP
+		}
+	}
+	if c.BranchThreshold == 0 {
+		if c.File != nil && c.File.BranchThreshold != 0 {
+			c.BranchThreshold = c.File.BranchThreshold
+		} else if c.Global != nil && c.Global.BranchThreshold != 0 {
+			c.BranchThreshold = c.Global.BranchThreshold
+		}
+	}
 	if c.Threshold != 0 {
 		return
 	}
@@ -68,13 +176,60 @@ func (c *Config) updateThreshold() {
 	}
 }
 
+// updateWatermarks picks the watermarks given explicitly, then the ones of the
+// .gocov file and then the global ones. The watermarks of the .gocov file are
+// validated when it is loaded already.
+func (c *Config) updateWatermarks() error {
+	switch {
+	case c.Watermarks != (Watermarks{}):
+		if c.Watermarks.Low > c.Watermarks.High {
+			return fmt.Errorf("invalid watermarks: low is greater than high")
+		}
+	case c.File != nil && c.File.Watermarks != nil:
+		c.Watermarks = *c.File.Watermarks
+	case c.Global != nil && c.Global.Watermarks != nil:
+		if c.Global.Watermarks.Low > c.Global.Watermarks.High {
+			return fmt.Errorf("invalid watermarks in global .gocov config file: low is greater than high")
+		}
+		c.Watermarks = *c.Global.Watermarks
+	default:
+		c.Watermarks = defaultWatermarks
+	}
+	return nil
+}
+
+// Watermarks define the coverage percentages at which the output turns
+// green (high) and yellow (low), anything below low is red.
+type Watermarks struct {
+	High float64 `json:"high"`
+	Low  float64 `json:"low"`
+}
+
+var defaultWatermarks = Watermarks{High: 80, Low: 50}
+
 type GocovConfig struct {
-	Ignore               []string `json:"ignore"`
-	Threshold            float64  `json:"threshold"`
-	ReadmeThresholdRegex string   `json:"readme_threshold_regex,omitempty"`
+	Ignore               []string    `json:"ignore"`
+	Threshold            float64     `json:"threshold"`
+	LineThreshold        float64     `json:"line_threshold,omitempty"`
+	BranchThreshold      float64     `json:"branch_threshold,omitempty"`
+	MaxCRAP              float64     `json:"max_crap,omitempty"`
+	ExportedThreshold    float64     `json:"exported_threshold,omitempty"`
+	ReadmeThresholdRegex string      `json:"readme_threshold_regex,omitempty"`
+	Output               string      `json:"output,omitempty"`
+	Watermarks           *Watermarks `json:"watermarks,omitempty"`
+	HTML                 *HTMLConfig `json:"html,omitempty"`
 	Contents             []byte
 }
 
+// HTMLConfig customises the html report, the template and the css are paths
+// relative to the root of the module.
+type HTMLConfig struct {
+	Template string `json:"template,omitempty"`
+	Title    string `json:"title,omitempty"`
+	CSS      string `json:"css,omitempty"`
+	Theme    string `json:"theme,omitempty"`
+}
+
 type covReport struct {
 	StartLine       int
 	StartColumn     int
@@ -90,6 +245,10 @@ type covFile struct {
 	AllStatements int
 	Percent       float64
 	Covered       int
+	Lines         int
+	LinesCovered  int
+	Branches      int
+	BranchesTaken int
 	Reports       []*covReport
 	reports       map[string]*covReport
 }
@@ -120,6 +279,27 @@ func (f *covFile) calc() {
 		}
 		f.Percent = float64(f.Covered) * 100 / float64(f.AllStatements)
 	}
+	f.calcLines()
+}
+
+// calcLines counts the source lines touched by blocks with statements, a
+// line is covered when any of the blocks touching it was hit.
+func (f *covFile) calcLines() {
+	lines := map[int]bool{}
+	for _, report := range f.Reports {
+		if report.StatementsCount == 0 {
+			continue
+		}
+		for line := report.StartLine; line <= report.EndLine; line++ {
+			lines[line] = lines[line] || report.Hits > 0
+		}
+	}
+	f.Lines = len(lines)
+	for _, covered := range lines {
+		if covered {
+			f.LinesCovered++
+		}
+	}
 }
 
 type Exiter interface {
@@ -160,6 +340,9 @@ func (cmd *Cmd) loadConfig() error {
 
 	if conf != nil {
 		conf.Contents = buf.Bytes()
+		if conf.Watermarks != nil && conf.Watermarks.Low > conf.Watermarks.High {
+			return fmt.Errorf("invalid watermarks in .gocov config file: low is greater than high")
+		}
 	}
 
 	cmd.config.File = conf
@@ -174,6 +357,7 @@ type Cmd struct {
 	config *Config
 	exiter Exiter
 	fw     FileWriterInterface
+	index  *testIndex
 }
 
 func NewCommand(stdout io.Writer, stderr io.Writer, fsys fs.StatFS, config *Config, exiter Exiter, fw FileWriterInterface) *Cmd {
@@ -265,13 +449,29 @@ func (cmd *Cmd) Exec(command Command, args []string) {
 		return
 	}
 
-	cmd.config.Update()
+	err = cmd.config.Update()
+	if err != nil {
+		if command == ConfigFile {
+			cmd.Config()
+			return
+		}
+
+		_, _ = fmt.Fprint(cmd.stderr, err.Error())
+		cmd.exiter.Exit(1)
+		return
+	}
 
 	if command == ConfigFile {
 		cmd.Config()
 		return
 	}
 
+	// the profiles of the stability runs are read by the command itself
+	if command == Stability {
+		cmd.Stability(args)
+		return
+	}
+
 	module, err := getModule(cmd.fsys)
 	if err != nil {
 		_, _ = fmt.Fprint(cmd.stderr, err.Error())
@@ -287,6 +487,16 @@ func (cmd *Cmd) Exec(command Command, args []string) {
 		cmd.exiter.Exit(1)
 		return
 	}
+	if cmd.config.Branches || (command == Check && cmd.config.BranchThreshold > 0) {
+		if err = cmd.calcBranches(files, moduleDir); err != nil {
+			_, _ = fmt.Fprint(cmd.stderr, err.Error())
+			cmd.exiter.Exit(1)
+			return
+		}
+	}
+	if cmd.config.Compact {
+		tree.Compact()
+	}
 	stats := tree.Accumulate()
 
 	if command == Inspect {
@@ -300,13 +510,24 @@ func (cmd *Cmd) Exec(command Command, args []string) {
 	}
 
 	if command == Check {
-		cmd.Check(tree)
+		cmd.Check(tree, files, moduleDir)
+		return
+	}
+
+	if command == Hotspots {
+		cmd.Hotspots(files, moduleDir)
 		return
 	}
-}
 
-func padPath(maxFileLen int, path string, indent int) string {
-	return strings.Repeat(" ", maxFileLen-len(path)-(indent*2))
+	if command == Affected {
+		cmd.Affected(files, module)
+		return
+	}
+
+	if command == Mutate {
+		cmd.Mutate(args, files, moduleDir)
+		return
+	}
 }
 
 func getPercent(n *Node) float64 {
@@ -316,16 +537,36 @@ func getPercent(n *Node) float64 {
 	return float64(n.covered) * 100 / float64(n.allStatements)
 }
 
+func getBranchPercent(n *Node) float64 {
+	if n.branches <= 0 {
+		return 0
+	}
+	return float64(n.branchesTaken) * 100 / float64(n.branches)
+}
+
+func getLinesPercent(n *Node) float64 {
+	if n.lines <= 0 {
+		return 0
+	}
+	return float64(n.linesCovered) * 100 / float64(n.lines)
+}
+
 func (t *Tree) Add(path string, value *covFile) {
 	t.Root.Add(path, path, value, 0)
 }
 
 func isIgnored(f *covFile, config *GocovConfig) bool {
+	return isIgnoredPath(f.Path, config)
+}
+
+// isIgnoredPath tells whether the .gocov file ignores the path of a file,
+// relative to the parent directory of the module.
+func isIgnoredPath(filePath string, config *GocovConfig) bool {
 	if config == nil {
 		return false
 	}
 	for _, ignore := range config.Ignore {
-		if strings.HasPrefix(f.Path, ignore) {
+		if strings.HasPrefix(filePath, ignore) {
 			return true
 		}
 	}
@@ -14,11 +14,17 @@ func (m *exiterMock) Exit(code int) {
 }
 
 type fileWriterMock struct {
-	f io.Writer
+	f       io.Writer
+	path    string
+	openErr error
 }
 
 func (fw *fileWriterMock) Open(filepath string) error {
+	if fw.openErr != nil {
+		return fw.openErr
+	}
 	fw.f = &bytes.Buffer{}
+	fw.path = filepath
 	return nil
 }
 
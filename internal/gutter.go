@@ -0,0 +1,206 @@
+package internal
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+const (
+	uncoveredMarker = "!"
+	coveredMarker   = "+"
+	testsColor      = "\033[90m"
+)
+
+// heatColors go from the least to the most executed lines.
+var heatColors = []string{
+	"\033[0;34m",
+	"\033[0;36m",
+	"\033[0;33m",
+	"\033[1;35m",
+}
+
+// gutter writes the source lines prefixed with their line number, and
+// optionally with the hit count of the line and markers for the lines which
+// can not be told apart by color.
+type gutter struct {
+	config    *Config
+	width     int
+	marked    map[int]bool
+	covered   map[int]bool
+	counts    map[int]int
+	tests     map[int][]string
+	lastTests string
+	lastLine  int
+	// open tells for each line whether it ends inside an uncovered region,
+	// which has to be reopened after the annotation.
+	open       []bool
+	start, end string
+	countWidth int
+	maxHits    int
+}
+
+func newGutter(config *Config, file *covFile, linesCount int) *gutter {
+	g := &gutter{
+		config: config,
+		width:  digitsCount(linesCount - 1),
+	}
+	if config.MarkUncovered && !config.HTMLOutput {
+		g.marked = uncoveredLines(file)
+	}
+	if config.MarkCovered && !config.HTMLOutput {
+		g.covered = coveredLines(file)
+	}
+	if config.Counts {
+		g.counts = lineHits(file)
+		for _, hits := range g.counts {
+			if hits > g.maxHits {
+				g.maxHits = hits
+			}
+		}
+		g.countWidth = digitsCount(g.maxHits)
+	}
+	return g
+}
+
+// gutterFor returns the gutter for the file, with the tests covering its
+// lines when they were asked for.
+func (cmd *Cmd) gutterFor(file *covFile, lines []string, linesCount int) (*gutter, error) {
+	g := newGutter(cmd.config, file, linesCount)
+	if !cmd.config.Tests {
+		return g, nil
+	}
+	if cmd.index == nil {
+		index, err := cmd.loadTestIndex()
+		if err != nil {
+			return nil, err
+		}
+		cmd.index = index
+	}
+	g.tests = cmd.index.lineTests(file)
+	g.start, g.end = cmd.uncoveredColors()
+	g.open = openColorAt(lines, g.start, g.end)
+	return g, nil
+}
+
+func (g *gutter) write(w io.Writer, num int, line string) {
+	if annotation := g.testsAnnotation(num + 1); annotation != "" {
+		// a hunk closes the region on its last line already
+		if g.open[num+1] && !strings.HasSuffix(line, g.end) {
+			annotation += g.start
+		}
+		line += annotation
+	}
+	marker := " "
+	switch {
+	case g.marked[num+1]:
+		marker = uncoveredMarker
+	case g.covered[num+1]:
+		marker = g.config.paint(Green) + coveredMarker + g.config.paint(NoColor)
+	}
+	number := fmt.Sprintf("%*d", g.width, num+1)
+	if names := g.tests[num+1]; g.config.HTMLOutput && len(names) > 0 {
+		number = `<span class="tests" title="` + html.EscapeString(strings.Join(names, ", ")) + `">` + number + `</span>`
+	}
+	if g.counts == nil {
+		_, _ = fmt.Fprintf(w, "%s|%s%s\n", number, marker, line)
+		return
+	}
+	_, _ = fmt.Fprintf(w, "%s|%s|%s%s\n", number, g.count(num+1), marker, line)
+}
+
+// testsAnnotation lists the tests covering the line in the terminal, only
+// when they differ from the ones of the previous line, so that long blocks
+// are annotated once.
+func (g *gutter) testsAnnotation(line int) string {
+	if g.tests == nil || g.config.HTMLOutput {
+		return ""
+	}
+	names := strings.Join(g.tests[line], ", ")
+	changed := names != g.lastTests || line != g.lastLine+1
+	g.lastTests, g.lastLine = names, line
+	if names == "" || !changed {
+		return ""
+	}
+	return "  " + g.config.paint(testsColor) + "// " + names + g.config.paint(NoColor)
+}
+
+// count renders the hit count cell for the line, lines without any blocks
+// are left blank.
+func (g *gutter) count(line int) string {
+	hits, ok := g.counts[line]
+	if !ok {
+		return fmt.Sprintf("%*s", g.countWidth, "")
+	}
+	cell := fmt.Sprintf("%*d", g.countWidth, hits)
+	if !g.config.Heat || hits == 0 {
+		return cell
+	}
+	level := heatLevel(hits, g.maxHits)
+	if g.config.HTMLOutput {
+		return `<span class="heat-` + strconv.Itoa(level+1) + `">` + cell + `</span>`
+	}
+	return g.config.paint(heatColors[level]) + cell + g.config.paint(NoColor)
+}
+
+// heatLevel buckets the hit count on a logarithmic scale relative to the
+// most executed line, so that a few very hot loops do not flatten the rest.
+func heatLevel(hits, maxHits int) int {
+	if maxHits <= 1 {
+		return 0
+	}
+	ratio := math.Log(float64(hits)) / math.Log(float64(maxHits))
+	level := int(ratio * float64(len(heatColors)))
+	if level >= len(heatColors) {
+		level = len(heatColors) - 1
+	}
+	return level
+}
+
+// lineHits returns the highest hit count of the blocks touching each line.
+func lineHits(file *covFile) map[int]int {
+	hits := map[int]int{}
+	for _, report := range file.Reports {
+		for line := report.StartLine; line <= report.EndLine; line++ {
+			if current, ok := hits[line]; !ok || report.Hits > current {
+				hits[line] = report.Hits
+			}
+		}
+	}
+	return hits
+}
+
+// uncoveredLines returns the line numbers touched by blocks which were not
+// covered.
+func uncoveredLines(file *covFile) map[int]bool {
+	lines := map[int]bool{}
+	for _, report := range file.Reports {
+		if report.Hits > 0 {
+			continue
+		}
+		for line := report.StartLine; line <= report.EndLine; line++ {
+			lines[line] = true
+		}
+	}
+	return lines
+}
+
+// coveredLines returns the line numbers touched only by covered blocks.
+func coveredLines(file *covFile) map[int]bool {
+	uncovered := uncoveredLines(file)
+	lines := map[int]bool{}
+	for _, report := range file.Reports {
+		if report.Hits == 0 {
+			continue
+		}
+		for line := report.StartLine; line <= report.EndLine; line++ {
+			if !uncovered[line] {
+				lines[line] = true
+			}
+		}
+	}
+	return lines
+}
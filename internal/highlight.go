@@ -0,0 +1,153 @@
+package internal
+
+import (
+	"errors"
+	"go/scanner"
+	"go/token"
+	"strings"
+)
+
+type tokenClass int
+
+const (
+	classPlain tokenClass = iota
+	classKeyword
+	classString
+	classComment
+	classNumber
+)
+
+// syntaxColors are the foreground colors of the token classes, the
+// uncovered code is marked with uncoveredBackground instead so that both
+// stay visible.
+var syntaxColors = map[tokenClass]string{
+	classKeyword: "34",
+	classString:  "32",
+	classComment: "90",
+	classNumber:  "36",
+}
+
+const uncoveredBackground = "48;5;52"
+
+var errStaleProfile = errors.New("running inspect failed, please regenerate the coverage report again")
+
+// highlightLines returns the lines of the source with its tokens colored
+// and the uncovered statements on a red background. Every line resets the
+// colors at its end, so that the lines can be printed on their own.
+func highlightLines(data []byte, file *covFile) ([]string, error) {
+	classes := classifyTokens(data)
+	uncovered, err := uncoveredBytes(data, file)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		lines []string
+		sb    strings.Builder
+		prev  string
+	)
+	for i := 0; i <= len(data); i++ {
+		if i == len(data) || data[i] == '\n' {
+			if prev != "" {
+				sb.WriteString(NoColor)
+			}
+			lines = append(lines, sb.String())
+			sb.Reset()
+			prev = ""
+			continue
+		}
+		style := sgr(classes[i], uncovered[i])
+		if style != prev {
+			if prev != "" {
+				sb.WriteString(NoColor)
+			}
+			sb.WriteString(style)
+			prev = style
+		}
+		sb.WriteByte(data[i])
+	}
+	return lines, nil
+}
+
+func sgr(class tokenClass, uncovered bool) string {
+	var params []string
+	if color, ok := syntaxColors[class]; ok {
+		params = append(params, color)
+	}
+	if uncovered {
+		params = append(params, uncoveredBackground)
+	}
+	if len(params) == 0 {
+		return ""
+	}
+	return "\033[" + strings.Join(params, ";") + "m"
+}
+
+// classifyTokens returns the token class of every byte of the source.
+func classifyTokens(data []byte) []tokenClass {
+	classes := make([]tokenClass, len(data))
+	fset := token.NewFileSet()
+	file := fset.AddFile("", fset.Base(), len(data))
+
+	var s scanner.Scanner
+	s.Init(file, data, nil, scanner.ScanComments)
+	for {
+		pos, tok, lit := s.Scan()
+		if tok == token.EOF {
+			break
+		}
+		var class tokenClass
+		switch {
+		case tok.IsKeyword():
+			class, lit = classKeyword, tok.String()
+		case tok == token.STRING || tok == token.CHAR:
+			class = classString
+		case tok == token.COMMENT:
+			class = classComment
+		case tok == token.INT || tok == token.FLOAT || tok == token.IMAG:
+			class = classNumber
+		default:
+			continue
+		}
+		offset := file.Offset(pos)
+		for i := offset; i < offset+len(lit) && i < len(classes); i++ {
+			classes[i] = class
+		}
+	}
+	return classes
+}
+
+// uncoveredBytes reports for every byte of the source whether it belongs to
+// a block which was not covered.
+func uncoveredBytes(data []byte, file *covFile) ([]bool, error) {
+	lines := strings.Split(string(data), "\n")
+	starts := make([]int, len(lines))
+	for i := 1; i < len(lines); i++ {
+		starts[i] = starts[i-1] + len(lines[i-1]) + 1
+	}
+	offset := func(line, column int) (int, error) {
+		if line < 1 || line > len(lines) || column-1 > len(lines[line-1]) {
+			return 0, errStaleProfile
+		}
+		return starts[line-1] + column - 1, nil
+	}
+
+	uncovered := make([]bool, len(data))
+	for _, report := range file.Reports {
+		if report.Hits > 0 {
+			continue
+		}
+		start, err := offset(report.StartLine, report.StartColumn)
+		if err != nil {
+			return nil, err
+		}
+		end, err := offset(report.EndLine, report.EndColumn)
+		if err != nil {
+			return nil, err
+		}
+		for i := start; i < end; i++ {
+			uncovered[i] = true
+		}
+	}
+	return uncovered, nil
+}
@@ -0,0 +1,174 @@
+package internal
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const defaultHotspotsTop = 10
+
+// hotspot is a function with its risk of change, measured by the CRAP
+// (Change Risk Anti-Patterns) score: complex functions with little coverage
+// score the highest.
+type hotspot struct {
+	fn         *funcInfo
+	complexity int
+	coverage   float64
+	crap       float64
+}
+
+func newHotspot(fn *funcInfo) *hotspot {
+	covered, all := fn.coverage()
+	// a function without statements has nothing left to cover
+	coverage := 1.0
+	if all > 0 {
+		coverage = float64(covered) / float64(all)
+	}
+	complexity := cyclomaticComplexity(fn.decl)
+	return &hotspot{
+		fn:         fn,
+		complexity: complexity,
+		coverage:   coverage,
+		crap:       crapScore(complexity, coverage),
+	}
+}
+
+// crapScore is comp^2 * (1 - cov)^3 + comp, with the coverage as a fraction.
+func crapScore(complexity int, coverage float64) float64 {
+	c := float64(complexity)
+	return c*c*math.Pow(1-coverage, 3) + c
+}
+
+// cyclomaticComplexity counts the decision points of the function plus one:
+// the if, for and range statements, the non default cases and the && and
+// || operators. Function literals count towards the enclosing function.
+func cyclomaticComplexity(decl *ast.FuncDecl) int {
+	complexity := 1
+	if decl.Body == nil {
+		return complexity
+	}
+	ast.Inspect(decl.Body, func(n ast.Node) bool {
+		switch s := n.(type) {
+		case *ast.IfStmt, *ast.ForStmt, *ast.RangeStmt:
+			complexity++
+		case *ast.CaseClause:
+			if s.List != nil {
+				complexity++
+			}
+		case *ast.CommClause:
+			if s.Comm != nil {
+				complexity++
+			}
+		case *ast.BinaryExpr:
+			if s.Op == token.LAND || s.Op == token.LOR {
+				complexity++
+			}
+		}
+		return true
+	})
+	return complexity
+}
+
+// hotspots returns the functions of the profile sorted from the riskiest.
+func (cmd *Cmd) hotspots(files map[string]*covFile, moduleDir string) ([]*hotspot, error) {
+	funcs, err := cmd.collectFuncs(cmd.reportTargets(files, moduleDir))
+	if err != nil {
+		return nil, err
+	}
+	spots := make([]*hotspot, 0, len(funcs))
+	for _, fn := range funcs {
+		spots = append(spots, newHotspot(fn))
+	}
+	sort.SliceStable(spots, func(i, j int) bool {
+		if spots[i].crap != spots[j].crap {
+			return spots[i].crap > spots[j].crap
+		}
+		return spots[i].fn.String() < spots[j].fn.String()
+	})
+	return spots, nil
+}
+
+func (cmd *Cmd) Hotspots(files map[string]*covFile, moduleDir string) {
+	if !isValidStyle(cmd.config.Style) {
+		_, _ = fmt.Fprintf(cmd.stderr, "invalid table style: %s", cmd.config.Style)
+		cmd.exiter.Exit(1)
+		return
+	}
+	if cmd.config.Top < 0 {
+		_, _ = fmt.Fprintf(cmd.stderr, "invalid number of functions: %d, --top can not be negative", cmd.config.Top)
+		cmd.exiter.Exit(1)
+		return
+	}
+	spots, err := cmd.hotspots(files, moduleDir)
+	if err != nil {
+		_, _ = fmt.Fprint(cmd.stderr, err.Error())
+		cmd.exiter.Exit(1)
+		return
+	}
+
+	top := cmd.config.Top
+	if top == 0 {
+		top = defaultHotspotsTop
+	}
+	if top < len(spots) {
+		spots = spots[:top]
+	}
+
+	columns := []*column{
+		{title: "Function", width: len("Function"), minWidth: len("Function")},
+		{title: "Location", width: len("Location"), minWidth: len("Location")},
+		{title: "Complexity", width: len("Complexity"), right: true},
+		{title: "% Stmts", width: 8, right: true},
+		{title: "CRAP", width: len("CRAP"), right: true},
+	}
+	rows := make([][]string, 0, len(spots))
+	for _, spot := range spots {
+		row := []string{
+			spot.fn.String(),
+			spot.fn.target.path + ":" + strconv.Itoa(spot.fn.start.Line),
+			strconv.Itoa(spot.complexity),
+			fmt.Sprintf("%7.2f%%", spot.coverage*100),
+			fmt.Sprintf("%.2f", spot.crap),
+		}
+		for i, cell := range row {
+			if len(cell) > columns[i].width {
+				columns[i].width = len(cell)
+			}
+		}
+		rows = append(rows, row)
+	}
+
+	tbl := newTable(cmd.stdout, cmd.config.Style, columns)
+	tbl.fit(cmd.config.Width)
+	tbl.header()
+	for i, row := range rows {
+		color, noColorValue := getColor(spots[i].coverage*100, cmd.config.Watermarks)
+		tbl.row(row, cmd.config.paint(color), cmd.config.paint(noColorValue))
+	}
+	tbl.footer()
+}
+
+// checkCRAP returns an error naming the functions whose CRAP score is above
+// the configured maximum.
+func (cmd *Cmd) checkCRAP(files map[string]*covFile, moduleDir string) error {
+	spots, err := cmd.hotspots(files, moduleDir)
+	if err != nil {
+		return err
+	}
+	var failed []string
+	for _, spot := range spots {
+		if spot.crap <= cmd.config.MaxCRAP {
+			break
+		}
+		failed = append(failed, fmt.Sprintf("%s (%.2f)", spot.fn, spot.crap))
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("Coverage check failed: expected a CRAP score of at most %.2f, but got %s", cmd.config.MaxCRAP, strings.Join(failed, ", "))
+	}
+	return nil
+}
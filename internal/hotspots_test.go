@@ -0,0 +1,144 @@
+//nolint:funlen
+package internal_test
+
+import (
+	"bytes"
+	"io/fs"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/slavsan/gocov/internal"
+)
+
+func TestHotspots(t *testing.T) {
+	testCases := []struct {
+		title            string
+		fsys             fs.StatFS
+		config           *internal.Config
+		expectedStdout   string
+		expectedStderr   string
+		expectedExitCode int
+	}{
+		{
+			title: "with the riskiest functions first",
+			fsys: fstest.MapFS{
+				"go.mod":       {Data: []byte(`module github.com/slavsan/gocov`)},
+				"coverage.out": {Data: []byte(exampleBranchCoverageOut)},
+				"main.go":      {Data: []byte(exampleCountSourceFile)},
+				"cmd/kind.go":  {Data: []byte(exampleBranchSourceFile)},
+			},
+			config: &internal.Config{
+				Color: false,
+			},
+			expectedStdout: strings.Join([]string{
+				`|-----------|---------------|------------|----------|------|`,
+				`| Function  | Location      | Complexity |  % Stmts | CRAP |`,
+				`|-----------|---------------|------------|----------|------|`,
+				`| cmd.Kind  | cmd/kind.go:3 |          3 |   75.00% | 3.14 |`,
+				`| main.work | main.go:9     |          2 |   50.00% | 2.50 |`,
+				`| main.main | main.go:3     |          2 |  100.00% | 2.00 |`,
+				`|-----------|---------------|------------|----------|------|`,
+				``,
+			}, "\n"),
+			expectedStderr:   "",
+			expectedExitCode: 0,
+		},
+		{
+			title: "with the functions of ignored files left out",
+			fsys: fstest.MapFS{
+				"go.mod":       {Data: []byte(`module github.com/slavsan/gocov`)},
+				"coverage.out": {Data: []byte(exampleBranchCoverageOut)},
+				"main.go":      {Data: []byte(exampleCountSourceFile)},
+				"cmd/kind.go":  {Data: []byte(exampleBranchSourceFile)},
+				".gocov":       {Data: []byte(`{"ignore": ["example/cmd"]}`)},
+			},
+			config: &internal.Config{
+				Color: false,
+			},
+			expectedStdout: strings.Join([]string{
+				`|-----------|-----------|------------|----------|------|`,
+				`| Function  | Location  | Complexity |  % Stmts | CRAP |`,
+				`|-----------|-----------|------------|----------|------|`,
+				`| main.work | main.go:9 |          2 |   50.00% | 2.50 |`,
+				`| main.main | main.go:3 |          2 |  100.00% | 2.00 |`,
+				`|-----------|-----------|------------|----------|------|`,
+				``,
+			}, "\n"),
+			expectedStderr:   "",
+			expectedExitCode: 0,
+		},
+		{
+			title: "with top N functions",
+			fsys: fstest.MapFS{
+				"go.mod":       {Data: []byte(`module github.com/slavsan/gocov`)},
+				"coverage.out": {Data: []byte(exampleBranchCoverageOut)},
+				"main.go":      {Data: []byte(exampleCountSourceFile)},
+				"cmd/kind.go":  {Data: []byte(exampleBranchSourceFile)},
+			},
+			config: &internal.Config{
+				Color: false,
+				Top:   1,
+			},
+			expectedStdout: strings.Join([]string{
+				`|----------|---------------|------------|----------|------|`,
+				`| Function | Location      | Complexity |  % Stmts | CRAP |`,
+				`|----------|---------------|------------|----------|------|`,
+				`| cmd.Kind | cmd/kind.go:3 |          3 |   75.00% | 3.14 |`,
+				`|----------|---------------|------------|----------|------|`,
+				``,
+			}, "\n"),
+			expectedStderr:   "",
+			expectedExitCode: 0,
+		},
+		{
+			title: "with a negative number of functions",
+			fsys: fstest.MapFS{
+				"go.mod":       {Data: []byte(`module github.com/slavsan/gocov`)},
+				"coverage.out": {Data: []byte(exampleBranchCoverageOut)},
+				"main.go":      {Data: []byte(exampleCountSourceFile)},
+				"cmd/kind.go":  {Data: []byte(exampleBranchSourceFile)},
+			},
+			config: &internal.Config{
+				Color: false,
+				Top:   -1,
+			},
+			expectedStdout:   "",
+			expectedStderr:   "invalid number of functions: -1, --top can not be negative",
+			expectedExitCode: 1,
+		},
+		{
+			title: "with a source file which does not parse",
+			fsys: fstest.MapFS{
+				"go.mod":       {Data: []byte(`module github.com/slavsan/gocov`)},
+				"coverage.out": {Data: []byte(exampleBranchCoverageOut)},
+				"main.go":      {Data: []byte(`package main; func {`)},
+			},
+			config: &internal.Config{
+				Color: false,
+			},
+			expectedStdout:   "",
+			expectedStderr:   "failed to parse main.go: main.go:1:20: expected 'IDENT', found '{'",
+			expectedExitCode: 1,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.title, func(t *testing.T) {
+			var stdout bytes.Buffer
+			var stderr bytes.Buffer
+			exiter := &exiterMock{}
+			internal.NewCommand(&stdout, &stderr, tc.fsys, tc.config, exiter, &fileWriterMock{}).Exec(internal.Hotspots, []string{})
+			if tc.expectedStdout != stdout.String() {
+				t.Errorf("table does not match\n\texpected:\n`%s`\n\tactual:\n`%s`\n", tc.expectedStdout, stdout.String())
+			}
+			if tc.expectedStderr != stderr.String() {
+				t.Errorf("table does not match\n\texpected:\n`%s`\n\tactual:\n`%s`\n", tc.expectedStderr, stderr.String())
+			}
+			if tc.expectedExitCode != exiter.code {
+				t.Errorf("exit code does not match\n\texpected:\n`%d`\n\tactual:\n`%d`\n", tc.expectedExitCode, exiter.code)
+			}
+		})
+	}
+}
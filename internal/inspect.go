@@ -4,15 +4,22 @@ import (
 	"errors"
 	"fmt"
 	"io"
-	"io/fs"
+	"path"
 	"sort"
 	"strings"
 )
 
-func findExactFile(args []string, files map[string]*covFile, moduleDir string) (*covFile, string, error) {
+// inspectTarget is a file selected for inspection, path is relative to the
+// module root.
+type inspectTarget struct {
+	file *covFile
+	path string
+}
+
+func newInspectTarget(name string, files map[string]*covFile, moduleDir string) *inspectTarget {
 	var (
 		targetFile string
-		relPath    = args[0]
+		relPath    = strings.TrimPrefix(name, moduleDir+"/")
 		index      = strings.IndexByte(relPath, '/')
 	)
 	if index == -1 {
@@ -20,43 +27,75 @@ func findExactFile(args []string, files map[string]*covFile, moduleDir string) (
 	} else {
 		targetFile = relPath[index+1:]
 	}
-	file, ok := files[moduleDir+"/"+relPath]
-	if !ok {
-		return nil, "", fmt.Errorf("failed to open %s", moduleDir+"/"+relPath) //nolint:goerr113
-	}
-	return file, targetFile, nil
+	return &inspectTarget{file: files[name], path: targetFile}
 }
 
-func findPartialMatchFile(args []string, files map[string]*covFile, moduleDir string) (*covFile, string, int, error) {
-	var (
-		targetFile string
-		skipped    int
-	)
-	sorted := make([]string, 0, len(files))
-	for k := range files {
-		if strings.Contains(k, args[0]) {
-			sorted = append(sorted, k)
+// findExactFiles looks up the files for an exact path, either to a single
+// file or to a package directory, in which case all of its files are
+// selected. The path can be relative to the module's parent directory
+// (as in the report's full path column) or a full import path.
+func findExactFiles(arg string, files map[string]*covFile, moduleDir string) ([]string, error) {
+	arg = strings.TrimPrefix(arg, "./")
+	for _, name := range []string{moduleDir + "/" + arg, arg} {
+		if _, ok := files[name]; ok {
+			return []string{name}, nil
 		}
 	}
-	if len(sorted) == 0 {
-		return nil, "", 0, errors.New("no file found for the given search")
+	var matches []string
+	for name := range files {
+		dir := path.Dir(name)
+		if dir == moduleDir+"/"+arg || dir == arg {
+			matches = append(matches, name)
+		}
 	}
-	sort.Strings(sorted)
-	firstMatch := sorted[0]
-	skipped = len(sorted) - 1
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("failed to open %s", moduleDir+"/"+arg) //nolint:goerr113
+	}
+	sort.Strings(matches)
+	return matches, nil
+}
 
-	relPath := strings.TrimPrefix(firstMatch, moduleDir+"/")
-	index := strings.IndexByte(relPath, '/')
-	if index == -1 {
-		targetFile = relPath
-	} else {
-		targetFile = relPath[index+1:]
+func findPartialMatchFiles(arg string, files map[string]*covFile) ([]string, error) {
+	var matches []string
+	for name := range files {
+		if strings.Contains(name, arg) {
+			matches = append(matches, name)
+		}
+	}
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("no file found for the given search: %s", arg)
 	}
-	file, ok := files[firstMatch]
-	if !ok {
-		return nil, "", 0, fmt.Errorf("failed to open %s", firstMatch)
+	sort.Strings(matches)
+	return matches, nil
+}
+
+// findTargets returns the files matching any of the arguments, in the order
+// of the arguments and without duplicates.
+func (cmd *Cmd) findTargets(args []string, files map[string]*covFile, moduleDir string) ([]*inspectTarget, error) {
+	var (
+		targets []*inspectTarget
+		seen    = map[string]bool{}
+		matches []string
+		err     error
+	)
+	for _, arg := range args {
+		if cmd.config.ExactPath {
+			matches, err = findExactFiles(arg, files, moduleDir)
+		} else {
+			matches, err = findPartialMatchFiles(arg, files)
+		}
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range matches {
+			if seen[name] {
+				continue
+			}
+			seen[name] = true
+			targets = append(targets, newInspectTarget(name, files, moduleDir))
+		}
 	}
-	return file, targetFile, skipped, nil
+	return targets, nil
 }
 
 func (cmd *Cmd) Inspect(args []string, files map[string]*covFile, moduleDir string) {
@@ -70,59 +109,243 @@ func (cmd *Cmd) Inspect(args []string, files map[string]*covFile, moduleDir stri
 }
 
 func (cmd *Cmd) inspect(args []string, files map[string]*covFile, moduleDir string) (string, error) {
+	var selectors, paths []string
+	for _, arg := range args {
+		if isFuncSelector(arg) {
+			selectors = append(selectors, arg)
+			continue
+		}
+		paths = append(paths, arg)
+	}
+	if cmd.config.Func != "" {
+		selectors = append(selectors, cmd.config.Func)
+	}
+	if len(selectors) > 0 {
+		return cmd.inspectFuncs(selectors, paths, files, moduleDir)
+	}
+
 	if len(args) < 1 {
 		return "", errors.New("no arguments provided to inspect command")
 	}
-	var (
-		targetFile string
-		err        error
-		f          fs.File
-		file       *covFile
-		data       []byte
-		skipped    int
-		sb         strings.Builder
-	)
-	if cmd.config.ExactPath {
-		file, targetFile, err = findExactFile(args, files, moduleDir)
-	} else {
-		file, targetFile, skipped, err = findPartialMatchFile(args, files, moduleDir)
+	targets, err := cmd.findTargets(args, files, moduleDir)
+	if err != nil {
+		return "", err
+	}
+
+	var sb strings.Builder
+	if cmd.config.ListFiles {
+		cmd.listTargets(&sb, targets)
+		return sb.String(), nil
+	}
+
+	for _, target := range targets {
+		if !cmd.config.ExactPath || len(targets) > 1 {
+			_, _ = fmt.Fprintf(&sb, "inspect for file: %s\n", target.path)
+		}
+		result, err := cmd.inspectFile(target.file, target.path)
+		if err != nil {
+			return "", err
+		}
+		sb.WriteString(result)
+	}
+
+	return sb.String(), nil
+}
+
+func (cmd *Cmd) listTargets(w io.Writer, targets []*inspectTarget) {
+	var maxLen int
+	for _, target := range targets {
+		if len(target.path) > maxLen {
+			maxLen = len(target.path)
+		}
+	}
+	for _, target := range targets {
+		color, noColorValue := getColor(target.file.Percent, cmd.config.Watermarks)
+		_, _ = fmt.Fprintf(w, "%-*s %s%7.2f%%%s\n",
+			maxLen, target.path, cmd.config.paint(color), target.file.Percent, cmd.config.paint(noColorValue),
+		)
+	}
+}
+
+// inspectFile renders the source of a single file with its uncovered
+// statements highlighted.
+func (cmd *Cmd) inspectFile(file *covFile, targetFile string) (string, error) {
+	var sb strings.Builder
+
+	lines, err := cmd.sourceLines(file, targetFile)
+	if err != nil {
+		return "", err
 	}
+
+	start, end := cmd.uncoveredColors()
+	g, err := cmd.gutterFor(file, lines, len(lines))
 	if err != nil {
 		return "", err
 	}
-	f, err = cmd.fsys.Open(targetFile)
+	if cmd.config.UncoveredOnly && !cmd.config.HTMLOutput {
+		writeHunks(&sb, lines, file, g, start, end, cmd.config.Context)
+	} else {
+		for num, line := range lines {
+			g.write(&sb, num, line)
+		}
+	}
+
+	return sb.String(), nil
+}
+
+const (
+	uncoveredStartTag = `<span class="uncovered">`
+	uncoveredEndTag   = `</span>`
+	// the uncovered statements of the html report are marked before the
+	// source is escaped, Go source files can not hold NUL bytes.
+	htmlUncoveredStart = "\x00start"
+	htmlUncoveredEnd   = "\x00end"
+)
+
+func (cmd *Cmd) uncoveredColors() (string, string) {
+	if cmd.config.HTMLOutput {
+		return uncoveredStartTag, uncoveredEndTag
+	}
+	// highlighted lines open and close their own colors
+	if cmd.highlight() {
+		return "", ""
+	}
+	return cmd.config.paint(Red), cmd.config.paint(NoColor)
+}
+
+func (cmd *Cmd) highlight() bool {
+	return cmd.config.Highlight && cmd.config.Color && !cmd.config.HTMLOutput
+}
+
+// sourceLines reads the target file and returns its lines with the
+// uncovered statements highlighted.
+func (cmd *Cmd) sourceLines(file *covFile, targetFile string) ([]string, error) {
+	f, err := cmd.fsys.Open(targetFile)
 	if err != nil {
-		return "", fmt.Errorf("failed to open file to inspect: %w", err)
+		return nil, fmt.Errorf("failed to open file to inspect: %w", err)
 	}
 	defer func() { _ = f.Close() }()
 
-	data, err = io.ReadAll(f)
+	data, err := io.ReadAll(f)
 	if err != nil {
-		return "", fmt.Errorf("failed to read target file to inspect: %w", err)
+		return nil, fmt.Errorf("failed to read target file to inspect: %w", err)
+	}
+
+	if cmd.highlight() {
+		return highlightLines(data, file)
 	}
 
-	start, end := Red, NoColor
 	if cmd.config.HTMLOutput {
-		start, end = "<span style=\"background: pink\">", "</span>"
+		lines, err := getColorizedLines(htmlUncoveredStart, htmlUncoveredEnd, data, file)
+		if err != nil {
+			return nil, err
+		}
+		for i := range lines {
+			lines[i] = escape(lines[i])
+		}
+		return lines, nil
 	}
 
-	lines, err := getColorizedLines(start, end, data, file)
-	if err != nil {
-		return "", err
+	start, end := cmd.uncoveredColors()
+	return getColorizedLines(start, end, data, file)
+}
+
+// hunk is a range of lines (zero based, inclusive) around one or more
+// uncovered blocks.
+type hunk struct {
+	first, last int
+	blocks      []*covReport
+}
+
+// getHunks groups the uncovered blocks into hunks with the given number of
+// context lines around them, merging the hunks which overlap or touch.
+func getHunks(file *covFile, context, linesCount int) []*hunk {
+	var blocks []*covReport
+	for _, report := range file.Reports {
+		if report.Hits == 0 && report.StatementsCount > 0 {
+			blocks = append(blocks, report)
+		}
 	}
+	sort.Slice(blocks, func(i, j int) bool {
+		if blocks[i].StartLine == blocks[j].StartLine {
+			return blocks[i].StartColumn < blocks[j].StartColumn
+		}
+		return blocks[i].StartLine < blocks[j].StartLine
+	})
 
-	width := digitsCount(len(lines) - 1)
-	if !cmd.config.ExactPath && !cmd.config.HTMLOutput {
-		_, _ = fmt.Fprintf(&sb, "inspect for file: %s\n", targetFile)
+	var hunks []*hunk
+	for _, block := range blocks {
+		first := block.StartLine - 1 - context
+		if first < 0 {
+			first = 0
+		}
+		last := block.EndLine - 1 + context
+		if last > linesCount-1 {
+			last = linesCount - 1
+		}
+		if len(hunks) > 0 && first <= hunks[len(hunks)-1].last+1 {
+			h := hunks[len(hunks)-1]
+			if last > h.last {
+				h.last = last
+			}
+			h.blocks = append(h.blocks, block)
+			continue
+		}
+		hunks = append(hunks, &hunk{first: first, last: last, blocks: []*covReport{block}})
 	}
-	for num, line := range lines {
-		_, _ = fmt.Fprintf(&sb, "%*d| %s\n", width, num+1, line)
+	return hunks
+}
+
+// writeHunks writes only the lines around the uncovered blocks, grep style.
+// A hunk can begin or end in the middle of an uncovered block, so the color
+// is reopened at the start of the hunk and closed at its end.
+func writeHunks(w io.Writer, lines []string, file *covFile, g *gutter, start, end string, context int) {
+	open := openColorAt(lines, start, end)
+	for i, h := range getHunks(file, context, len(lines)) {
+		if i != 0 {
+			_, _ = fmt.Fprint(w, "--\n")
+		}
+		for _, block := range h.blocks {
+			_, _ = fmt.Fprintf(w, "@@ %d.%d,%d.%d: %s not covered @@\n",
+				block.StartLine, block.StartColumn, block.EndLine, block.EndColumn,
+				pluralize(block.StatementsCount, "statement"),
+			)
+		}
+		for num := h.first; num <= h.last; num++ {
+			line := lines[num]
+			if num == h.first && open[num] {
+				line = start + line
+			}
+			if num == h.last && open[num+1] {
+				line += end
+			}
+			g.write(w, num, line)
+		}
+	}
+}
+
+// openColorAt reports for each line whether it starts inside a colored
+// region, the last element is the state after the last line.
+func openColorAt(lines []string, start, end string) []bool {
+	open := make([]bool, len(lines)+1)
+	if start == "" {
+		return open
 	}
-	if !cmd.config.ExactPath && skipped > 0 {
-		_, _ = fmt.Fprintf(&sb, "skipped %d other files which matched\n", skipped)
+	for i, line := range lines {
+		open[i+1] = open[i]
+		s, e := strings.LastIndex(line, start), strings.LastIndex(line, end)
+		if s != -1 || e != -1 {
+			open[i+1] = s > e
+		}
 	}
+	return open
+}
 
-	return sb.String(), nil
+func pluralize(count int, noun string) string {
+	if count == 1 {
+		return fmt.Sprintf("%d %s", count, noun)
+	}
+	return fmt.Sprintf("%d %ss", count, noun)
 }
 
 func getColorizedLines(start, end string, data []byte, file *covFile) ([]string, error) {
@@ -143,11 +366,11 @@ func getColorizedLines(start, end string, data []byte, file *covFile) ([]string,
 		lineNum := report.EndLine - 1
 
 		if len(lines) < lineNum+1 {
-			return nil, errors.New("running inspect failed, please regenerate the coverage report again")
+			return nil, errStaleProfile
 		}
 
 		if len(lines[lineNum]) < report.EndColumn-1 {
-			return nil, errors.New("running inspect failed, please regenerate the coverage report again")
+			return nil, errStaleProfile
 		}
 
 		lines[lineNum] = lines[lineNum][:report.EndColumn-1] + end + lines[lineNum][report.EndColumn-1:]
@@ -155,11 +378,11 @@ func getColorizedLines(start, end string, data []byte, file *covFile) ([]string,
 		lineNum = report.StartLine - 1
 
 		if len(lines) < lineNum+1 {
-			return nil, errors.New("running inspect failed, please regenerate the coverage report again")
+			return nil, errStaleProfile
 		}
 
 		if len(lines[lineNum]) < report.StartColumn-1 {
-			return nil, errors.New("running inspect failed, please regenerate the coverage report again")
+			return nil, errStaleProfile
 		}
 
 		lines[lineNum] = lines[lineNum][:report.StartColumn-1] + start + lines[lineNum][report.StartColumn-1:]
@@ -4,6 +4,8 @@ package internal_test
 import (
 	"bytes"
 	"io/fs"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"testing/fstest"
@@ -70,7 +72,7 @@ func TestInspect(t *testing.T) { //nolint:maintidx
 			},
 			args: []string{"gocov/cmd/gocov.go"},
 			config: &internal.Config{
-				Color:     false,
+				Color:     true,
 				ExactPath: true,
 			},
 			expectedStdout: strings.Join([]string{
@@ -118,6 +120,599 @@ func TestInspect(t *testing.T) { //nolint:maintidx
 			expectedStderr:   "",
 			expectedExitCode: 0,
 		},
+		{
+			title: "when printing the entire file without colors",
+			fsys: fstest.MapFS{
+				"go.mod":       {Data: []byte(`module github.com/slavsan/gospec`)},
+				"coverage.out": {Data: []byte(exampleCoverageOut3)},
+				"cmd/gocov.go": {Data: []byte(strings.Join([]string{
+					`package cmd`,
+					``,
+					`import (`,
+					`	"os"`,
+					``,
+					`	"github.com/slavsan/gocov/internal"`,
+					`)`,
+					``,
+					`func Exec() {`,
+					`	var args []string`,
+					`	config := &internal.Config{}`,
+					`	config.Color = true`,
+					``,
+					`	command := internal.Report`,
+					``,
+					`	if len(os.Args) > 1 {`,
+					`		switch os.Args[1] {`,
+					`		case "check":`,
+					`			command = internal.Check`,
+					`		case "inspect":`,
+					`			command = internal.Inspect`,
+					`			if len(os.Args) > 2 {`,
+					`				args = append(args, os.Args[2])`,
+					`			}`,
+					`			//os.Args[1]`,
+					`		}`,
+					`	}`,
+					``,
+					`	internal.Exec(`,
+					`		command,`,
+					`		args,`,
+					`		os.Stdout,`,
+					`		os.Stderr,`,
+					`		os.DirFS("."),`,
+					`		config,`,
+					`		&internal.ProcessExiter{},`,
+					`	)`,
+					`}`,
+					``,
+				}, "\n"))},
+			},
+			args: []string{"gocov/cmd/gocov.go"},
+			config: &internal.Config{
+				Color:     false,
+				ExactPath: true,
+			},
+			expectedStdout: strings.Join([]string{
+				` 1| package cmd`,
+				` 2| `,
+				` 3| import (`,
+				` 4| 	"os"`,
+				` 5| `,
+				` 6| 	"github.com/slavsan/gocov/internal"`,
+				` 7| )`,
+				` 8| `,
+				` 9| func Exec() {`,
+				`10| 	var args []string`,
+				`11| 	config := &internal.Config{}`,
+				`12| 	config.Color = true`,
+				`13| `,
+				`14| 	command := internal.Report`,
+				`15| `,
+				`16| 	if len(os.Args) > 1 {`,
+				`17| 		switch os.Args[1] {`,
+				`18| 		case "check":`,
+				`19| 			command = internal.Check`,
+				`20| 		case "inspect":`,
+				`21| 			command = internal.Inspect`,
+				`22| 			if len(os.Args) > 2 {`,
+				`23| 				args = append(args, os.Args[2])`,
+				`24| 			}`,
+				`25| 			//os.Args[1]`,
+				`26| 		}`,
+				`27| 	}`,
+				`28| `,
+				`29| 	internal.Exec(`,
+				`30| 		command,`,
+				`31| 		args,`,
+				`32| 		os.Stdout,`,
+				`33| 		os.Stderr,`,
+				`34| 		os.DirFS("."),`,
+				`35| 		config,`,
+				`36| 		&internal.ProcessExiter{},`,
+				`37| 	)`,
+				`38| }`,
+				`39| `,
+				``,
+			}, "\n"),
+			expectedStderr:   "",
+			expectedExitCode: 0,
+		},
+		{
+			title: "when printing only the uncovered blocks",
+			fsys: fstest.MapFS{
+				"go.mod":       {Data: []byte(`module github.com/slavsan/gospec`)},
+				"coverage.out": {Data: []byte(exampleCoverageOut3)},
+				"cmd/gocov.go": {Data: []byte(exampleSourceFile3)},
+			},
+			args: []string{"gocov/cmd/gocov.go"},
+			config: &internal.Config{
+				Color:         true,
+				ExactPath:     true,
+				UncoveredOnly: true,
+				Context:       1,
+			},
+			expectedStdout: strings.Join([]string{
+				`@@ 9.13,16.22: 5 statements not covered @@`,
+				`@@ 16.22,17.21: 1 statement not covered @@`,
+				`@@ 18.16,19.28: 1 statement not covered @@`,
+				`@@ 20.18,22.24: 2 statements not covered @@`,
+				`@@ 22.24,24.5: 1 statement not covered @@`,
+				` 8| `,
+				` 9| func Exec() ` + internal.Red + `{`,
+				`10| 	var args []string`,
+				`11| 	config := &internal.Config{}`,
+				`12| 	config.Color = true`,
+				`13| `,
+				`14| 	command := internal.Report`,
+				`15| `,
+				`16| 	if len(os.Args) > 1 ` + internal.NoColor + internal.Red + `{`,
+				`17| 		switch os.Args[1] ` + internal.NoColor + `{`,
+				`18| 		case "check":` + internal.Red,
+				`19| 			command = internal.Check` + internal.NoColor,
+				`20| 		case "inspect":` + internal.Red,
+				`21| 			command = internal.Inspect`,
+				`22| 			if len(os.Args) > 2 ` + internal.NoColor + internal.Red + `{`,
+				`23| 				args = append(args, os.Args[2])`,
+				`24| 			}` + internal.NoColor,
+				`25| 			//os.Args[1]`,
+				`--`,
+				`@@ 29.2,37.3: 1 statement not covered @@`,
+				`28| `,
+				`29| 	` + internal.Red + `internal.Exec(`,
+				`30| 		command,`,
+				`31| 		args,`,
+				`32| 		os.Stdout,`,
+				`33| 		os.Stderr,`,
+				`34| 		os.DirFS("."),`,
+				`35| 		config,`,
+				`36| 		&internal.ProcessExiter{},`,
+				`37| 	)` + internal.NoColor,
+				`38| }`,
+				``,
+			}, "\n"),
+			expectedStderr:   "",
+			expectedExitCode: 0,
+		},
+		{
+			title: "when printing only the uncovered blocks without colors",
+			fsys: fstest.MapFS{
+				"go.mod":       {Data: []byte(`module github.com/slavsan/gospec`)},
+				"coverage.out": {Data: []byte(exampleCoverageOut3)},
+				"cmd/gocov.go": {Data: []byte(exampleSourceFile3)},
+			},
+			args: []string{"gocov/cmd/gocov.go"},
+			config: &internal.Config{
+				Color:         false,
+				ExactPath:     true,
+				UncoveredOnly: true,
+				MarkUncovered: true,
+				Context:       0,
+			},
+			expectedStdout: strings.Join([]string{
+				`@@ 9.13,16.22: 5 statements not covered @@`,
+				`@@ 16.22,17.21: 1 statement not covered @@`,
+				`@@ 18.16,19.28: 1 statement not covered @@`,
+				`@@ 20.18,22.24: 2 statements not covered @@`,
+				`@@ 22.24,24.5: 1 statement not covered @@`,
+				` 9|!func Exec() {`,
+				`10|!	var args []string`,
+				`11|!	config := &internal.Config{}`,
+				`12|!	config.Color = true`,
+				`13|!`,
+				`14|!	command := internal.Report`,
+				`15|!`,
+				`16|!	if len(os.Args) > 1 {`,
+				`17|!		switch os.Args[1] {`,
+				`18|!		case "check":`,
+				`19|!			command = internal.Check`,
+				`20|!		case "inspect":`,
+				`21|!			command = internal.Inspect`,
+				`22|!			if len(os.Args) > 2 {`,
+				`23|!				args = append(args, os.Args[2])`,
+				`24|!			}`,
+				`--`,
+				`@@ 29.2,37.3: 1 statement not covered @@`,
+				`29|!	internal.Exec(`,
+				`30|!		command,`,
+				`31|!		args,`,
+				`32|!		os.Stdout,`,
+				`33|!		os.Stderr,`,
+				`34|!		os.DirFS("."),`,
+				`35|!		config,`,
+				`36|!		&internal.ProcessExiter{},`,
+				`37|!	)`,
+				``,
+			}, "\n"),
+			expectedStderr:   "",
+			expectedExitCode: 0,
+		},
+		{
+			title: "when printing the hit counts",
+			fsys: fstest.MapFS{
+				"go.mod":       {Data: []byte(`module github.com/slavsan/gospec`)},
+				"coverage.out": {Data: []byte(exampleCountCoverageOut)},
+				"main.go":      {Data: []byte(exampleCountSourceFile)},
+			},
+			args: []string{"example/main.go"},
+			config: &internal.Config{
+				Color:     false,
+				ExactPath: true,
+				Counts:    true,
+			},
+			expectedStdout: strings.Join([]string{
+				` 1|   | package main`,
+				` 2|   | `,
+				` 3|  1| func main() {`,
+				` 4|100| 	for i := 0; i < 100; i++ {`,
+				` 5|100| 		work(i)`,
+				` 6|100| 	}`,
+				` 7|   | }`,
+				` 8|   | `,
+				` 9|100| func work(i int) {`,
+				`10|100| 	if i < 0 {`,
+				`11|  0| 		panic(i)`,
+				`12|  0| 	}`,
+				`13|   | }`,
+				`14|   | `,
+				``,
+			}, "\n"),
+			expectedStderr:   "",
+			expectedExitCode: 0,
+		},
+		{
+			title: "when coloring the hit counts by heat",
+			fsys: fstest.MapFS{
+				"go.mod":       {Data: []byte(`module github.com/slavsan/gospec`)},
+				"coverage.out": {Data: []byte(exampleCountCoverageOut)},
+				"main.go":      {Data: []byte(exampleCountSourceFile)},
+			},
+			args: []string{"example/main.go"},
+			config: &internal.Config{
+				Color:         true,
+				ExactPath:     true,
+				Counts:        true,
+				Heat:          true,
+				UncoveredOnly: true,
+				Context:       1,
+			},
+			expectedStdout: strings.Join([]string{
+				`@@ 10.11,12.3: 1 statement not covered @@`,
+				` 9|` + "\033[1;35m" + `100` + internal.NoColor + `| func work(i int) {`,
+				`10|` + "\033[1;35m" + `100` + internal.NoColor + `| 	if i < 0 ` + internal.Red + `{`,
+				`11|  0| 		panic(i)`,
+				`12|  0| 	}` + internal.NoColor,
+				`13|   | }`,
+				``,
+			}, "\n"),
+			expectedStderr:   "",
+			expectedExitCode: 0,
+		},
+		{
+			title: "when inspecting a function",
+			fsys: fstest.MapFS{
+				"go.mod":       {Data: []byte(`module github.com/slavsan/gospec`)},
+				"coverage.out": {Data: []byte(exampleCountCoverageOut)},
+				"main.go":      {Data: []byte(exampleCountSourceFile)},
+			},
+			args: []string{},
+			config: &internal.Config{
+				Color: false,
+				Func:  "work",
+			},
+			expectedStdout: strings.Join([]string{
+				`func main.work in main.go: 1/2 statements covered (50.00%), 1/2 branches taken`,
+				` 9| func work(i int) {`,
+				`10| 	if i < 0 {`,
+				`11| 		panic(i)`,
+				`12| 	}`,
+				`13| }`,
+				``,
+			}, "\n"),
+			expectedStderr:   "",
+			expectedExitCode: 0,
+		},
+		{
+			title: "when inspecting a method by its qualified name",
+			fsys: fstest.MapFS{
+				"go.mod": {Data: []byte(`module github.com/slavsan/gospec`)},
+				"coverage.out": {Data: []byte(strings.Join([]string{
+					`mode: set`,
+					`example/cmd/cmd.go:5.28,6.13 1 1`,
+					`example/cmd/cmd.go:6.13,8.3 1 1`,
+					`example/cmd/cmd.go:9.2,9.14 1 0`,
+					`example/main.go:3.13,4.26 1 1`,
+					``,
+				}, "\n"))},
+				"cmd/cmd.go": {Data: []byte(strings.Join([]string{
+					`package cmd`,
+					``,
+					`type Cmd struct{ n int }`,
+					``,
+					`func (c *Cmd) check() bool {`,
+					`	if c.n > 0 {`,
+					`		return true`,
+					`	}`,
+					`	return false`,
+					`}`,
+					``,
+				}, "\n"))},
+			},
+			args: []string{"cmd.(*Cmd).check"},
+			config: &internal.Config{
+				Color: true,
+			},
+			expectedStdout: strings.Join([]string{
+				`func cmd.(*Cmd).check in cmd/cmd.go: 2/3 statements covered (66.67%), 1/2 branches taken`,
+				` 5| func (c *Cmd) check() bool {`,
+				` 6| 	if c.n > 0 {`,
+				` 7| 		return true`,
+				` 8| 	}`,
+				` 9| 	` + internal.Red + `return false` + internal.NoColor,
+				`10| }`,
+				``,
+			}, "\n"),
+			expectedStderr:   "",
+			expectedExitCode: 0,
+		},
+		{
+			title: "when no function matches the name",
+			fsys: fstest.MapFS{
+				"go.mod":       {Data: []byte(`module github.com/slavsan/gospec`)},
+				"coverage.out": {Data: []byte(exampleCountCoverageOut)},
+				"main.go":      {Data: []byte(exampleCountSourceFile)},
+			},
+			args: []string{"main.go"},
+			config: &internal.Config{
+				Color: false,
+				Func:  "Cmd.check",
+			},
+			expectedStdout:   "",
+			expectedStderr:   "no function found for the given name: Cmd.check",
+			expectedExitCode: 1,
+		},
+		{
+			title: "when highlighting the syntax",
+			fsys: fstest.MapFS{
+				"go.mod": {Data: []byte(`module github.com/slavsan/gospec`)},
+				"coverage.out": {Data: []byte(strings.Join([]string{
+					`mode: set`,
+					`example/cmd/cmd.go:5.28,6.13 1 1`,
+					`example/cmd/cmd.go:6.13,8.3 1 1`,
+					`example/cmd/cmd.go:9.2,9.14 1 0`,
+					``,
+				}, "\n"))},
+				"cmd/cmd.go": {Data: []byte(strings.Join([]string{
+					`package cmd`,
+					``,
+					`type Cmd struct{ n int }`,
+					``,
+					`func (c *Cmd) check() bool {`,
+					`	if c.n > 0 {`,
+					`		return true // ok`,
+					`	}`,
+					`	return false`,
+					`}`,
+					``,
+				}, "\n"))},
+			},
+			args: []string{"example/cmd/cmd.go"},
+			config: &internal.Config{
+				Color:       true,
+				ExactPath:   true,
+				Highlight:   true,
+				MarkCovered: true,
+			},
+			expectedStdout: strings.Join([]string{
+				` 1| ` + "\033[34m" + `package` + internal.NoColor + ` cmd`,
+				` 2| `,
+				` 3| ` + "\033[34m" + `type` + internal.NoColor + ` Cmd ` + "\033[34m" + `struct` + internal.NoColor + `{ n int }`,
+				` 4| `,
+				` 5|` + internal.Green + `+` + internal.NoColor + "\033[34m" + `func` + internal.NoColor + ` (c *Cmd) check() bool {`,
+				` 6|` + internal.Green + `+` + internal.NoColor + `	` + "\033[34m" + `if` + internal.NoColor + ` c.n > ` + "\033[36m" + `0` + internal.NoColor + ` {`,
+				` 7|` + internal.Green + `+` + internal.NoColor + `		` + "\033[34m" + `return` + internal.NoColor + ` true ` + "\033[90m" + `// ok` + internal.NoColor,
+				` 8|` + internal.Green + `+` + internal.NoColor + `	}`,
+				` 9| 	` + "\033[34;48;5;52m" + `return` + internal.NoColor + "\033[48;5;52m" + ` false` + internal.NoColor,
+				`10| }`,
+				`11| `,
+				``,
+			}, "\n"),
+			expectedStderr:   "",
+			expectedExitCode: 0,
+		},
+		{
+			title: "when annotating the lines with their tests",
+			fsys: fstest.MapFS{
+				"go.mod":       {Data: []byte(`module github.com/slavsan/gospec`)},
+				"coverage.out": {Data: []byte(exampleCountCoverageOut)},
+				"main.go":      {Data: []byte(exampleCountSourceFile)},
+				".gocov-cache/index.json": {Data: []byte(strings.Join([]string{
+					`{"tests":[{"package":"example","name":"TestMain"},{"package":"example","name":"TestWork"}],`,
+					`"blocks":{"example/main.go:3.13,4.26":[0],"example/main.go:4.26,6.3":[0],"example/main.go:9.18,10.11":[0,1]}}`,
+				}, ""))},
+			},
+			args: []string{"example/main.go"},
+			config: &internal.Config{
+				Color:     false,
+				ExactPath: true,
+				Tests:     true,
+			},
+			expectedStdout: strings.Join([]string{
+				` 1| package main`,
+				` 2| `,
+				` 3| func main() {  // TestMain`,
+				` 4| 	for i := 0; i < 100; i++ {`,
+				` 5| 		work(i)`,
+				` 6| 	}`,
+				` 7| }`,
+				` 8| `,
+				` 9| func work(i int) {  // TestMain, TestWork`,
+				`10| 	if i < 0 {`,
+				`11| 		panic(i)`,
+				`12| 	}`,
+				`13| }`,
+				`14| `,
+				``,
+			}, "\n"),
+			expectedStderr:   "",
+			expectedExitCode: 0,
+		},
+		{
+			title: "when annotating the lines of a hunk with their tests",
+			fsys: fstest.MapFS{
+				"go.mod":       {Data: []byte(`module github.com/slavsan/gospec`)},
+				"coverage.out": {Data: []byte(exampleCountCoverageOut)},
+				"main.go":      {Data: []byte(exampleCountSourceFile)},
+				".gocov-cache/index.json": {Data: []byte(strings.Join([]string{
+					`{"tests":[{"package":"example","name":"TestMain"},{"package":"example","name":"TestWork"}],`,
+					`"blocks":{"example/main.go:3.13,4.26":[0],"example/main.go:4.26,6.3":[0],"example/main.go:9.18,10.11":[0,1]}}`,
+				}, ""))},
+			},
+			args: []string{"example/main.go"},
+			config: &internal.Config{
+				Color:         true,
+				ExactPath:     true,
+				Tests:         true,
+				UncoveredOnly: true,
+				Context:       1,
+			},
+			expectedStdout: strings.Join([]string{
+				`@@ 10.11,12.3: 1 statement not covered @@`,
+				` 9| func work(i int) {  ` + "\033[90m" + `// TestMain, TestWork` + internal.NoColor,
+				`10| 	if i < 0 ` + internal.Red + `{`,
+				`11| 		panic(i)`,
+				`12| 	}` + internal.NoColor,
+				`13| }`,
+				``,
+			}, "\n"),
+			expectedStderr:   "",
+			expectedExitCode: 0,
+		},
+		{
+			title: "when annotating the lines with their tests without a test index",
+			fsys: fstest.MapFS{
+				"go.mod":       {Data: []byte(`module github.com/slavsan/gospec`)},
+				"coverage.out": {Data: []byte(exampleCountCoverageOut)},
+				"main.go":      {Data: []byte(exampleCountSourceFile)},
+			},
+			args: []string{"example/main.go"},
+			config: &internal.Config{
+				Color:     false,
+				ExactPath: true,
+				Tests:     true,
+			},
+			expectedStdout:   "",
+			expectedStderr:   "no per-test coverage found in .gocov-cache, run `gocov test --per-test` first",
+			expectedExitCode: 1,
+		},
+		{
+			title: "when listing the files of a package directory",
+			fsys: fstest.MapFS{
+				"go.mod":       {Data: []byte(`module github.com/slavsan/gocov`)},
+				"coverage.out": {Data: []byte(exampleCoverageOut6)},
+			},
+			args: []string{"example/internal"},
+			config: &internal.Config{
+				Color:     false,
+				ExactPath: true,
+				ListFiles: true,
+			},
+			expectedStdout: strings.Join([]string{
+				`internal/exec.go   50.00%`,
+				``,
+			}, "\n"),
+			expectedStderr:   "",
+			expectedExitCode: 0,
+		},
+		{
+			title: "when inspecting several files",
+			fsys: fstest.MapFS{
+				"go.mod":       {Data: []byte(`module github.com/slavsan/gospec`)},
+				"coverage.out": {Data: []byte(exampleCoverageOut6)},
+				"cmd/exec.go": {Data: []byte(strings.Join([]string{
+					`package cmd`,
+					``,
+					`import "example/internal"`,
+					``,
+					`func Exec() {`,
+					`	internal.Exec(1, 2, 3)`,
+					`}`,
+					``,
+				}, "\n"))},
+				"main.go": {Data: []byte(strings.Join([]string{
+					`package main`,
+					``,
+					`import "example/cmd"`,
+					``,
+					`func main() {`,
+					`	cmd.Exec()`,
+					`}`,
+					``,
+				}, "\n"))},
+			},
+			args: []string{"example/main.go", "example/cmd/exec.go", "example/main.go"},
+			config: &internal.Config{
+				Color:     false,
+				ExactPath: true,
+			},
+			expectedStdout: strings.Join([]string{
+				`inspect for file: main.go`,
+				`1| package main`,
+				`2| `,
+				`3| import "example/cmd"`,
+				`4| `,
+				`5| func main() {`,
+				`6| 	cmd.Exec()`,
+				`7| }`,
+				`8| `,
+				`inspect for file: cmd/exec.go`,
+				`1| package cmd`,
+				`2| `,
+				`3| import "example/internal"`,
+				`4| `,
+				`5| func Exec() {`,
+				`6| 	internal.Exec(1, 2, 3)`,
+				`7| }`,
+				`8| `,
+				``,
+			}, "\n"),
+			expectedStderr:   "",
+			expectedExitCode: 0,
+		},
+		{
+			title: "when listing the files matching a search",
+			fsys: fstest.MapFS{
+				"go.mod":       {Data: []byte(`module github.com/slavsan/gospec`)},
+				"coverage.out": {Data: []byte(exampleCoverageOut)},
+			},
+			args: []string{"spec"},
+			config: &internal.Config{
+				Color:     false,
+				ListFiles: true,
+			},
+			expectedStdout: strings.Join([]string{
+				`cmd/cover.go      0.00%`,
+				`expect.go        50.00%`,
+				`featurespec.go   85.98%`,
+				`gospec.go       100.00%`,
+				``,
+			}, "\n"),
+			expectedStderr:   "",
+			expectedExitCode: 0,
+		},
+		{
+			title: "when no file matches the search",
+			fsys: fstest.MapFS{
+				"go.mod":       {Data: []byte(`module github.com/slavsan/gospec`)},
+				"coverage.out": {Data: []byte(exampleCoverageOut)},
+			},
+			args: []string{"gospec.go", "missing.go"},
+			config: &internal.Config{
+				Color: false,
+			},
+			expectedStdout:   "",
+			expectedStderr:   "no file found for the given search: missing.go",
+			expectedExitCode: 1,
+		},
 		{
 			title: "when no arguments provided",
 			fsys: fstest.MapFS{
@@ -197,7 +792,7 @@ func TestInspect(t *testing.T) { //nolint:maintidx
 			},
 			args: []string{"gocov.go"},
 			config: &internal.Config{
-				Color: false,
+				Color: true,
 			},
 			expectedStdout: strings.Join([]string{
 				`inspect for file: cmd/gocov.go`,
@@ -240,7 +835,8 @@ func TestInspect(t *testing.T) { //nolint:maintidx
 				`37| 	)` + internal.NoColor,
 				`38| }`,
 				`39| `,
-				`skipped 1 other files which matched`,
+				`inspect for file: internal/gocov.go`,
+				`1| `,
 				``,
 			}, "\n"),
 			expectedStderr:   "",
@@ -406,3 +1002,32 @@ func TestInspect(t *testing.T) { //nolint:maintidx
 		})
 	}
 }
+
+func TestInspectTestsWithAbsoluteCacheDir(t *testing.T) {
+	cacheDir := t.TempDir()
+	index := `{"tests":[{"package":"example","name":"TestWork"}],"blocks":{"example/main.go:9.18,10.11":[0]}}`
+	if err := os.WriteFile(filepath.Join(cacheDir, "index.json"), []byte(index), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	fsys := fstest.MapFS{
+		"go.mod":       {Data: []byte(`module github.com/slavsan/gospec`)},
+		"coverage.out": {Data: []byte(exampleCountCoverageOut)},
+		"main.go":      {Data: []byte(exampleCountSourceFile)},
+	}
+
+	var (
+		stdout bytes.Buffer
+		stderr bytes.Buffer
+		exiter = &exiterMock{}
+	)
+	config := &internal.Config{ExactPath: true, Tests: true, CacheDir: cacheDir}
+	internal.NewCommand(&stdout, &stderr, fsys, config, exiter, &fileWriterMock{}).Exec(internal.Inspect, []string{"example/main.go"})
+
+	expected := ` 9| func work(i int) {  // TestWork`
+	if stderr.String() != "" || exiter.code != 0 {
+		t.Fatalf("unexpected failure: %s (exit code %d)", stderr.String(), exiter.code)
+	}
+	if !strings.Contains(stdout.String(), expected+"\n") {
+		t.Errorf("output does not contain\n%s\n\tactual:\n%s\n", expected, stdout.String())
+	}
+}
@@ -0,0 +1,438 @@
+package internal
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const defaultMutateTimeout = "30s"
+
+// flippedOperators maps each comparison operator to its opposite.
+var flippedOperators = map[token.Token]token.Token{
+	token.EQL: token.NEQ,
+	token.NEQ: token.EQL,
+	token.LSS: token.GEQ,
+	token.GEQ: token.LSS,
+	token.GTR: token.LEQ,
+	token.LEQ: token.GTR,
+}
+
+// mutant is a single change to the source of a file, replacing the bytes
+// between the start and end offsets.
+type mutant struct {
+	target      *inspectTarget
+	pos         token.Position
+	start       int
+	end         int
+	replacement string
+	description string
+	killed      bool
+	skipped     bool
+}
+
+func (m *mutant) String() string {
+	return fmt.Sprintf("%s:%d:%d %s", m.target.path, m.pos.Line, m.pos.Column, m.description)
+}
+
+// pkg is the package of the mutant as a go test argument.
+func (m *mutant) pkg() string {
+	return "./" + path.Dir(m.target.path)
+}
+
+// hitAt tells whether the position is in a block which the tests hit.
+func hitAt(reports []*covReport, pos token.Position) bool {
+	for _, report := range reports {
+		if report.Hits == 0 {
+			continue
+		}
+		afterStart := pos.Line > report.StartLine ||
+			(pos.Line == report.StartLine && pos.Column >= report.StartColumn)
+		beforeEnd := pos.Line < report.EndLine ||
+			(pos.Line == report.EndLine && pos.Column < report.EndColumn)
+		if afterStart && beforeEnd {
+			return true
+		}
+	}
+	return false
+}
+
+// fileMutants returns the mutants of the covered code of the file: the
+// comparison operators flipped, the if and for conditions negated and the
+// returned values replaced with zero values.
+func fileMutants(fset *token.FileSet, parsed *ast.File, src []byte, target *inspectTarget) []*mutant {
+	var mutants []*mutant
+	text := func(node ast.Node) string {
+		return string(src[fset.Position(node.Pos()).Offset:fset.Position(node.End()).Offset])
+	}
+	add := func(pos, end token.Pos, replacement, description string) {
+		position := fset.Position(pos)
+		if !hitAt(target.file.Reports, position) {
+			return
+		}
+		mutants = append(mutants, &mutant{
+			target:      target,
+			pos:         position,
+			start:       position.Offset,
+			end:         fset.Position(end).Offset,
+			replacement: replacement,
+			description: description,
+		})
+	}
+	negate := func(cond ast.Expr, stmt string) {
+		if cond != nil {
+			add(cond.Pos(), cond.End(), "!("+text(cond)+")", "negated the "+stmt+" condition")
+		}
+	}
+
+	var visit func(body *ast.BlockStmt, results *ast.FieldList)
+	visit = func(body *ast.BlockStmt, results *ast.FieldList) {
+		ast.Inspect(body, func(n ast.Node) bool {
+			switch node := n.(type) {
+			case *ast.FuncLit:
+				visit(node.Body, node.Type.Results)
+				return false
+			case *ast.BinaryExpr:
+				if flipped, ok := flippedOperators[node.Op]; ok {
+					add(node.OpPos, node.OpPos+token.Pos(len(node.Op.String())), flipped.String(),
+						"replaced "+node.Op.String()+" with "+flipped.String())
+				}
+			case *ast.IfStmt:
+				negate(node.Cond, "if")
+			case *ast.ForStmt:
+				negate(node.Cond, "for")
+			case *ast.ReturnStmt:
+				zeros := zeroValues(results, text)
+				if len(node.Results) == 0 || len(zeros) != len(node.Results) {
+					return true
+				}
+				values := make([]string, 0, len(node.Results))
+				for _, result := range node.Results {
+					values = append(values, text(result))
+				}
+				if strings.Join(values, ", ") == strings.Join(zeros, ", ") {
+					return true
+				}
+				add(node.Results[0].Pos(), node.Results[len(node.Results)-1].End(), strings.Join(zeros, ", "),
+					"replaced the returned values with "+strings.Join(zeros, ", "))
+			}
+			return true
+		})
+	}
+	for _, decl := range parsed.Decls {
+		if fn, ok := decl.(*ast.FuncDecl); ok && fn.Body != nil {
+			visit(fn.Body, fn.Type.Results)
+		}
+	}
+
+	sort.SliceStable(mutants, func(i, j int) bool {
+		return mutants[i].start < mutants[j].start
+	})
+	return mutants
+}
+
+// zeroValues returns the zero value of each result of a function, as source.
+func zeroValues(results *ast.FieldList, text func(ast.Node) string) []string {
+	if results == nil {
+		return nil
+	}
+	var zeros []string
+	for _, field := range results.List {
+		zero := zeroValue(field.Type, text)
+		if len(field.Names) == 0 {
+			zeros = append(zeros, zero)
+			continue
+		}
+		for range field.Names {
+			zeros = append(zeros, zero)
+		}
+	}
+	return zeros
+}
+
+func zeroValue(typ ast.Expr, text func(ast.Node) string) string {
+	switch t := typ.(type) {
+	case *ast.Ident:
+		switch t.Name {
+		case "bool":
+			return "false"
+		case "string":
+			return `""`
+		case "error", "any":
+			return "nil"
+		case "int", "int8", "int16", "int32", "int64",
+			"uint", "uint8", "uint16", "uint32", "uint64", "uintptr",
+			"float32", "float64", "complex64", "complex128", "byte", "rune":
+			return "0"
+		}
+	case *ast.StarExpr, *ast.MapType, *ast.ChanType, *ast.FuncType, *ast.InterfaceType:
+		return "nil"
+	case *ast.ArrayType:
+		if t.Len == nil {
+			return "nil"
+		}
+	}
+	return "*new(" + text(typ) + ")"
+}
+
+// Mutate applies the mutants of the covered code one at a time to a copy of
+// the module and runs the tests of the mutated package. The mutants the
+// tests do not catch survive.
+func (cmd *Cmd) Mutate(args []string, files map[string]*covFile, moduleDir string) {
+	if !isValidStyle(cmd.config.Style) {
+		_, _ = fmt.Fprintf(cmd.stderr, "invalid table style: %s", cmd.config.Style)
+		cmd.exiter.Exit(1)
+		return
+	}
+	if err := cmd.mutate(args, files, moduleDir); err != nil {
+		_, _ = fmt.Fprint(cmd.stderr, err.Error())
+		cmd.exiter.Exit(1)
+	}
+}
+
+func (cmd *Cmd) mutate(args []string, files map[string]*covFile, moduleDir string) error {
+	targets := cmd.reportTargets(files, moduleDir)
+	if len(args) > 0 {
+		var err error
+		if targets, err = cmd.findTargets(args, files, moduleDir); err != nil {
+			return err
+		}
+	}
+
+	var (
+		mutants []*mutant
+		sources = map[string][]byte{}
+	)
+	for _, target := range targets {
+		src, err := fs.ReadFile(cmd.fsys, target.path)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", target.path, err)
+		}
+		fset, parsed, err := cmd.parseSource(target.path)
+		if err != nil {
+			return err
+		}
+		sources[target.path] = src
+		mutants = append(mutants, fileMutants(fset, parsed, src, target)...)
+	}
+
+	if cmd.config.ListMutants {
+		for _, m := range mutants {
+			_, _ = fmt.Fprintf(cmd.stdout, "%s\n", m)
+		}
+		return nil
+	}
+	if len(mutants) == 0 {
+		_, _ = fmt.Fprint(cmd.stdout, "no mutants in the covered code\n")
+		return nil
+	}
+
+	dir, err := os.MkdirTemp("", "gocov-mutate-")
+	if err != nil {
+		return fmt.Errorf("failed to create the module copy: %w", err)
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+	if err = cmd.copyModule(dir); err != nil {
+		return err
+	}
+
+	// the mutants of packages whose tests do not pass as they are can not
+	// be told apart from the failures which were already there
+	passing := map[string]bool{}
+	for _, m := range mutants {
+		pkg := m.pkg()
+		if _, ok := passing[pkg]; ok {
+			continue
+		}
+		_, err = cmd.testPackage(dir, pkg)
+		passing[pkg] = err == nil
+		if err != nil {
+			_, _ = fmt.Fprintf(cmd.stderr, "the tests of %s fail without any mutant, skipping its mutants\n", pkg)
+		}
+	}
+
+	for i, m := range mutants {
+		if !passing[m.pkg()] {
+			m.skipped = true
+			continue
+		}
+		_, _ = fmt.Fprintf(cmd.stdout, "testing mutant %d/%d: %s\n", i+1, len(mutants), m)
+		if err = cmd.testMutant(dir, m, sources[m.target.path]); err != nil {
+			return err
+		}
+	}
+
+	cmd.mutationTable(mutants)
+
+	var survived []*mutant
+	for _, m := range mutants {
+		if !m.killed && !m.skipped {
+			survived = append(survived, m)
+		}
+	}
+	if len(survived) > 0 {
+		_, _ = fmt.Fprint(cmd.stdout, "\nSurvived mutants:\n")
+		for _, m := range survived {
+			_, _ = fmt.Fprintf(cmd.stdout, "  %s\n", m)
+		}
+	}
+	return nil
+}
+
+// copyModule copies the files of the module to the directory, leaving out
+// the git and gocov cache directories.
+func (cmd *Cmd) copyModule(dir string) error {
+	return fs.WalkDir(cmd.fsys, ".", func(name string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			if name == ".git" || name == cmd.config.cacheDir() {
+				return fs.SkipDir
+			}
+			return os.MkdirAll(filepath.Join(dir, filepath.FromSlash(name)), 0o755)
+		}
+		if !entry.Type().IsRegular() {
+			return nil
+		}
+		data, err := fs.ReadFile(cmd.fsys, name)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(filepath.Join(dir, filepath.FromSlash(name)), data, 0o644) //nolint:gosec
+	})
+}
+
+// testMutant writes the mutated file to the module copy, runs the tests of
+// its package and restores the file. The mutant is killed when the tests
+// fail, mutants which do not build or whose tests do not run are skipped.
+func (cmd *Cmd) testMutant(dir string, m *mutant, src []byte) error {
+	file := filepath.Join(dir, filepath.FromSlash(m.target.path))
+	mutated := make([]byte, 0, len(src)+len(m.replacement))
+	mutated = append(mutated, src[:m.start]...)
+	mutated = append(mutated, m.replacement...)
+	mutated = append(mutated, src[m.end:]...)
+	if err := os.WriteFile(file, mutated, 0o644); err != nil { //nolint:gosec
+		return fmt.Errorf("failed to write the mutant: %w", err)
+	}
+	defer func() { _ = os.WriteFile(file, src, 0o644) }() //nolint:gosec
+
+	output, err := cmd.testPackage(dir, m.pkg())
+	if err != nil {
+		m.killed = testsFailed(output)
+		m.skipped = !m.killed
+	}
+	return nil
+}
+
+// testPackage runs the tests of the package in the module copy and returns
+// their output.
+func (cmd *Cmd) testPackage(dir, pkg string) (string, error) {
+	timeout := cmd.config.Timeout
+	if timeout == "" {
+		timeout = defaultMutateTimeout
+	}
+	var output bytes.Buffer
+	testCmd := exec.Command("go", "test", "-count=1", "-failfast", "-vet=off", "-timeout", timeout, pkg)
+	testCmd.Dir = dir
+	testCmd.Stdout = &output
+	testCmd.Stderr = &output
+	err := testCmd.Run()
+	return output.String(), err
+}
+
+// testsFailed tells whether the output of go test reports failed tests, as
+// opposed to a package which does not build or whose tests could not run.
+func testsFailed(output string) bool {
+	if strings.Contains(output, "[build failed]") || strings.Contains(output, "[setup failed]") {
+		return false
+	}
+	for _, line := range strings.Split(output, "\n") {
+		if strings.HasPrefix(line, "--- FAIL") || strings.HasPrefix(line, "FAIL") {
+			return true
+		}
+	}
+	return false
+}
+
+// mutationTable prints the mutation score of each file beside its
+// statement coverage.
+func (cmd *Cmd) mutationTable(mutants []*mutant) {
+	type fileScore struct {
+		target   *inspectTarget
+		killed   int
+		survived int
+	}
+	var (
+		scores []*fileScore
+		byPath = map[string]*fileScore{}
+	)
+	for _, m := range mutants {
+		if m.skipped {
+			continue
+		}
+		score, ok := byPath[m.target.path]
+		if !ok {
+			score = &fileScore{target: m.target}
+			byPath[m.target.path] = score
+			scores = append(scores, score)
+		}
+		if m.killed {
+			score.killed++
+		} else {
+			score.survived++
+		}
+	}
+
+	columns := []*column{
+		{title: "File", width: len("File"), minWidth: len("File")},
+		{title: "Mutants", width: len("Mutants"), right: true},
+		{title: "Killed", width: len("Killed"), right: true},
+		{title: "Survived", width: len("Survived"), right: true},
+		{title: "% Mutation", width: len("% Mutation"), right: true},
+		{title: "% Stmts", width: 8, right: true},
+	}
+	rows := make([][]string, 0, len(scores))
+	percents := make([]float64, 0, len(scores))
+	for _, score := range scores {
+		mutationPercent := percent(score.killed, score.killed+score.survived)
+		row := []string{
+			score.target.path,
+			strconv.Itoa(score.killed + score.survived),
+			strconv.Itoa(score.killed),
+			strconv.Itoa(score.survived),
+			fmt.Sprintf("%7.2f%%", mutationPercent),
+			fmt.Sprintf("%7.2f%%", percent(score.target.file.Covered, score.target.file.AllStatements)),
+		}
+		for i, cell := range row {
+			if len(cell) > columns[i].width {
+				columns[i].width = len(cell)
+			}
+		}
+		rows = append(rows, row)
+		percents = append(percents, mutationPercent)
+	}
+
+	_, _ = fmt.Fprint(cmd.stdout, "\n")
+	tbl := newTable(cmd.stdout, cmd.config.Style, columns)
+	tbl.fit(cmd.config.Width)
+	tbl.header()
+	for i, row := range rows {
+		color, noColorValue := getColor(percents[i], cmd.config.Watermarks)
+		tbl.row(row, cmd.config.paint(color), cmd.config.paint(noColorValue))
+	}
+	tbl.footer()
+}
@@ -0,0 +1,256 @@
+//nolint:funlen
+package internal_test
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os/exec"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/slavsan/gocov/internal"
+)
+
+func TestMutate(t *testing.T) {
+	testCases := []struct {
+		title            string
+		fsys             fs.StatFS
+		config           *internal.Config
+		args             []string
+		expectedStdout   string
+		expectedStderr   string
+		expectedExitCode int
+	}{
+		{
+			title: "when listing the mutants of the covered code",
+			fsys: fstest.MapFS{
+				"go.mod":       {Data: []byte(`module github.com/slavsan/gocov`)},
+				"coverage.out": {Data: []byte(exampleBranchCoverageOut)},
+				"main.go":      {Data: []byte(exampleCountSourceFile)},
+				"cmd/kind.go":  {Data: []byte(exampleBranchSourceFile)},
+			},
+			config: &internal.Config{
+				Color:       false,
+				ListMutants: true,
+			},
+			expectedStdout: strings.Join([]string{
+				`cmd/kind.go:8:10 replaced the returned values with ""`,
+				`cmd/kind.go:10:10 replaced the returned values with ""`,
+				`main.go:4:14 negated the for condition`,
+				`main.go:4:16 replaced < with >=`,
+				`main.go:10:5 negated the if condition`,
+				`main.go:10:7 replaced < with >=`,
+				``,
+			}, "\n"),
+			expectedStderr:   "",
+			expectedExitCode: 0,
+		},
+		{
+			title: "when listing the mutants without the ignored files",
+			fsys: fstest.MapFS{
+				"go.mod":       {Data: []byte(`module github.com/slavsan/gocov`)},
+				"coverage.out": {Data: []byte(exampleBranchCoverageOut)},
+				"main.go":      {Data: []byte(exampleCountSourceFile)},
+				"cmd/kind.go":  {Data: []byte(exampleBranchSourceFile)},
+				".gocov":       {Data: []byte(`{"ignore": ["example/cmd"]}`)},
+			},
+			config: &internal.Config{
+				Color:       false,
+				ListMutants: true,
+			},
+			expectedStdout: strings.Join([]string{
+				`main.go:4:14 negated the for condition`,
+				`main.go:4:16 replaced < with >=`,
+				`main.go:10:5 negated the if condition`,
+				`main.go:10:7 replaced < with >=`,
+				``,
+			}, "\n"),
+			expectedStderr:   "",
+			expectedExitCode: 0,
+		},
+		{
+			title: "when listing the mutants of the given files",
+			fsys: fstest.MapFS{
+				"go.mod":       {Data: []byte(`module github.com/slavsan/gocov`)},
+				"coverage.out": {Data: []byte(exampleBranchCoverageOut)},
+				"main.go":      {Data: []byte(exampleCountSourceFile)},
+				"cmd/kind.go":  {Data: []byte(exampleBranchSourceFile)},
+			},
+			config: &internal.Config{
+				Color:       false,
+				ListMutants: true,
+			},
+			args: []string{"kind.go"},
+			expectedStdout: strings.Join([]string{
+				`cmd/kind.go:8:10 replaced the returned values with ""`,
+				`cmd/kind.go:10:10 replaced the returned values with ""`,
+				``,
+			}, "\n"),
+			expectedStderr:   "",
+			expectedExitCode: 0,
+		},
+		{
+			title: "with the zero values of the result types",
+			fsys: fstest.MapFS{
+				"go.mod": {Data: []byte(`module github.com/slavsan/gocov`)},
+				"coverage.out": {Data: []byte(strings.Join([]string{
+					`mode: set`,
+					`example/sdk/client.go:5.41,7.2 1 1`,
+					`example/sdk/client.go:9.36,11.2 1 1`,
+					``,
+				}, "\n"))},
+				"sdk/client.go": {Data: []byte(strings.Join([]string{
+					`package sdk`,
+					``,
+					`type Options struct{ Retries int }`,
+					``,
+					`func Load(name string) (*Options, error) {`,
+					`	return &Options{Retries: len(name)}, nil`,
+					`}`,
+					``,
+					`func Defaults() (Options, []string) {`,
+					`	return Options{Retries: 3}, []string{"a"}`,
+					`}`,
+					``,
+				}, "\n"))},
+			},
+			config: &internal.Config{
+				Color:       false,
+				ListMutants: true,
+			},
+			expectedStdout: strings.Join([]string{
+				`sdk/client.go:6:9 replaced the returned values with nil, nil`,
+				`sdk/client.go:10:9 replaced the returned values with *new(Options), nil`,
+				``,
+			}, "\n"),
+			expectedStderr:   "",
+			expectedExitCode: 0,
+		},
+		{
+			title: "with a file which is not in the profile",
+			fsys: fstest.MapFS{
+				"go.mod":       {Data: []byte(`module github.com/slavsan/gocov`)},
+				"coverage.out": {Data: []byte(exampleBranchCoverageOut)},
+				"main.go":      {Data: []byte(exampleCountSourceFile)},
+			},
+			config: &internal.Config{
+				Color:       false,
+				ListMutants: true,
+			},
+			args:             []string{"missing.go"},
+			expectedStdout:   "",
+			expectedStderr:   "no file found for the given search: missing.go",
+			expectedExitCode: 1,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.title, func(t *testing.T) {
+			var stdout bytes.Buffer
+			var stderr bytes.Buffer
+			exiter := &exiterMock{}
+			internal.NewCommand(&stdout, &stderr, tc.fsys, tc.config, exiter, &fileWriterMock{}).Exec(internal.Mutate, tc.args)
+			if tc.expectedStdout != stdout.String() {
+				t.Errorf("output does not match\n\texpected:\n`%s`\n\tactual:\n`%s`\n", tc.expectedStdout, stdout.String())
+			}
+			if tc.expectedStderr != stderr.String() {
+				t.Errorf("output does not match\n\texpected:\n`%s`\n\tactual:\n`%s`\n", tc.expectedStderr, stderr.String())
+			}
+			if tc.expectedExitCode != exiter.code {
+				t.Errorf("exit code does not match\n\texpected:\n`%d`\n\tactual:\n`%d`\n", tc.expectedExitCode, exiter.code)
+			}
+		})
+	}
+}
+
+func TestMutateBaseline(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("the go command is needed to run the tests of the mutants")
+	}
+	source := strings.Join([]string{
+		`package %s`,
+		``,
+		`func Max(a, b int) int {`,
+		`	if a > b {`,
+		`		return a`,
+		`	}`,
+		`	return b`,
+		`}`,
+		``,
+	}, "\n")
+	fsys := fstest.MapFS{
+		"go.mod": {Data: []byte("module example\n\ngo 1.19\n")},
+		"coverage.out": {Data: []byte(strings.Join([]string{
+			`mode: set`,
+			`example/broken/max.go:3.24,4.11 1 1`,
+			`example/broken/max.go:4.11,6.3 1 1`,
+			`example/broken/max.go:7.2,7.10 1 1`,
+			`example/tested/max.go:3.24,4.11 1 1`,
+			`example/tested/max.go:4.11,6.3 1 1`,
+			`example/tested/max.go:7.2,7.10 1 1`,
+			``,
+		}, "\n"))},
+		"broken/max.go": {Data: []byte(fmt.Sprintf(source, "broken"))},
+		"broken/max_test.go": {Data: []byte(strings.Join([]string{
+			`package broken`,
+			``,
+			`import "testing"`,
+			``,
+			`func TestMax(t *testing.T) {`,
+			`	if Max(1, 2) != 2 || Max(2, 1) != 2 {`,
+			`		t.Fatal("wrong max")`,
+			`	}`,
+			`	t.Fatal("fails before any mutant")`,
+			`}`,
+			``,
+		}, "\n"))},
+		"tested/max.go": {Data: []byte(fmt.Sprintf(source, "tested"))},
+		"tested/max_test.go": {Data: []byte(strings.Join([]string{
+			`package tested`,
+			``,
+			`import "testing"`,
+			``,
+			`func TestMax(t *testing.T) {`,
+			`	if Max(1, 2) != 2 || Max(2, 1) != 2 {`,
+			`		t.Fatal("wrong max")`,
+			`	}`,
+			`}`,
+			``,
+		}, "\n"))},
+	}
+
+	var (
+		stdout bytes.Buffer
+		stderr bytes.Buffer
+		exiter = &exiterMock{}
+	)
+	config := &internal.Config{Color: false}
+	internal.NewCommand(&stdout, &stderr, fsys, config, exiter, &fileWriterMock{}).Exec(internal.Mutate, nil)
+
+	expectedStdout := strings.Join([]string{
+		`testing mutant 5/8: tested/max.go:4:5 negated the if condition`,
+		`testing mutant 6/8: tested/max.go:4:7 replaced > with <=`,
+		`testing mutant 7/8: tested/max.go:5:10 replaced the returned values with 0`,
+		`testing mutant 8/8: tested/max.go:7:9 replaced the returned values with 0`,
+		``,
+		`|---------------|---------|--------|----------|------------|----------|`,
+		`| File          | Mutants | Killed | Survived | % Mutation |  % Stmts |`,
+		`|---------------|---------|--------|----------|------------|----------|`,
+		`| tested/max.go |       4 |      4 |        0 |    100.00% |  100.00% |`,
+		`|---------------|---------|--------|----------|------------|----------|`,
+		``,
+	}, "\n")
+	expectedStderr := "the tests of ./broken fail without any mutant, skipping its mutants\n"
+	if expectedStdout != stdout.String() {
+		t.Errorf("output does not match\n\texpected:\n`%s`\n\tactual:\n`%s`\n", expectedStdout, stdout.String())
+	}
+	if expectedStderr != stderr.String() {
+		t.Errorf("output does not match\n\texpected:\n`%s`\n\tactual:\n`%s`\n", expectedStderr, stderr.String())
+	}
+	if exiter.code != 0 {
+		t.Errorf("exit code does not match\n\texpected:\n`0`\n\tactual:\n`%d`\n", exiter.code)
+	}
+}
@@ -0,0 +1,233 @@
+package internal
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const defaultCacheDir = ".gocov-cache"
+
+// testIndex maps the blocks of the profile to the tests which hit them. The
+// blocks are keyed as in the coverage profile, file:line.column,line.column,
+// and refer to the tests by their position in Tests.
+type testIndex struct {
+	Tests  []indexedTest    `json:"tests"`
+	Blocks map[string][]int `json:"blocks"`
+}
+
+type indexedTest struct {
+	Package string `json:"package"`
+	Name    string `json:"name"`
+}
+
+func blockKey(name string, report *covReport) string {
+	return fmt.Sprintf("%s:%d.%d,%d.%d", name, report.StartLine, report.StartColumn, report.EndLine, report.EndColumn)
+}
+
+func (c *Config) cacheDir() string {
+	if c.CacheDir == "" {
+		return defaultCacheDir
+	}
+	return c.CacheDir
+}
+
+// readFile reads a file of the module, or any file given by an absolute
+// path, like the ones in a cache directory outside of the module, which the
+// file system of the module can not open.
+func (cmd *Cmd) readFile(name string) ([]byte, error) {
+	if filepath.IsAbs(name) {
+		return os.ReadFile(name)
+	}
+	return fs.ReadFile(cmd.fsys, name)
+}
+
+// testNamePattern matches the top level tests in the output of go test -list.
+var testNamePattern = regexp.MustCompile(`^Test[\p{L}\p{N}_]*$`)
+
+// parseTestList groups the tests listed by go test -list by package, the
+// names of a package come before its "ok" line.
+func parseTestList(output string) map[string][]string {
+	var (
+		tests   = map[string][]string{}
+		pending []string
+	)
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if testNamePattern.MatchString(line) {
+			pending = append(pending, line)
+			continue
+		}
+		if fields := strings.Fields(line); len(fields) >= 2 && fields[0] == "ok" {
+			if len(pending) > 0 {
+				tests[fields[1]] = pending
+			}
+			pending = nil
+		}
+	}
+	return tests
+}
+
+// testPerTest runs every test on its own with a coverage profile of its own
+// and indexes which blocks each test hits.
+func (cmd *Cmd) testPerTest() error {
+	listArgs := []string{"test", "-list", ".", "./..."}
+	_, _ = fmt.Fprintf(cmd.stdout, "executing: go %s\n", strings.Join(listArgs, " "))
+	var listOutput bytes.Buffer
+	listCmd := exec.Command("go", listArgs...)
+	listCmd.Stdout = &listOutput
+	listCmd.Stderr = cmd.stderr
+	if err := listCmd.Run(); err != nil {
+		return fmt.Errorf("failed to list the tests: %w", err)
+	}
+
+	tests := parseTestList(listOutput.String())
+	packages := make([]string, 0, len(tests))
+	for pkg := range tests {
+		packages = append(packages, pkg)
+	}
+	sort.Strings(packages)
+
+	// the ids are positional, so a profile left by a previous run would be
+	// taken for the one of a test which did not get to write its own
+	profilesDir := path.Join(cmd.config.cacheDir(), "profiles")
+	if err := os.RemoveAll(profilesDir); err != nil {
+		return fmt.Errorf("failed to clear the cache directory: %w", err)
+	}
+	if err := os.MkdirAll(profilesDir, 0o755); err != nil {
+		return fmt.Errorf("failed to create the cache directory: %w", err)
+	}
+
+	index := &testIndex{Blocks: map[string][]int{}}
+	var failed []string
+	for _, pkg := range packages {
+		for _, name := range tests[pkg] {
+			id := len(index.Tests)
+			index.Tests = append(index.Tests, indexedTest{Package: pkg, Name: name})
+			profile := path.Join(profilesDir, strconv.Itoa(id)+".out")
+			runArgs := []string{"test", "-run", "^" + name + "$", "-coverprofile", profile, "-coverpkg", "./...", pkg}
+			_, _ = fmt.Fprintf(cmd.stdout, "executing: go %s\n", strings.Join(runArgs, " "))
+			runCmd := exec.Command("go", runArgs...)
+			runCmd.Stdout = cmd.stdout
+			runCmd.Stderr = cmd.stderr
+			if err := runCmd.Run(); err != nil {
+				failed = append(failed, name)
+			}
+			if err := cmd.indexProfile(index, id, profile); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := cmd.writeTestIndex(index); err != nil {
+		return err
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("failed to run tests: %s", strings.Join(failed, ", ")) //nolint:goerr113
+	}
+	return nil
+}
+
+// indexProfile adds the blocks hit in the profile of a single test to the
+// index. A missing profile means the test did not get to run.
+func (cmd *Cmd) indexProfile(index *testIndex, id int, profile string) error {
+	data, err := cmd.readFile(profile)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", profile, err)
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Scan() // skip the `mode` line
+	for scanner.Scan() {
+		line := scanner.Text()
+		colonIndex := strings.Index(line, ":")
+		if colonIndex == -1 {
+			continue
+		}
+		report, err := parseLine(line[colonIndex+1:])
+		if err != nil {
+			return fmt.Errorf("failed to parse %s: %w", profile, err)
+		}
+		if report.Hits == 0 {
+			continue
+		}
+		key := blockKey(line[:colonIndex], report)
+		ids := index.Blocks[key]
+		if len(ids) == 0 || ids[len(ids)-1] != id {
+			index.Blocks[key] = append(ids, id)
+		}
+	}
+	return nil
+}
+
+func (cmd *Cmd) writeTestIndex(index *testIndex) error {
+	b, err := json.Marshal(index)
+	if err != nil {
+		return fmt.Errorf("failed to encode the test index: %w", err)
+	}
+	if err = cmd.fw.Open(path.Join(cmd.config.cacheDir(), "index.json")); err != nil {
+		return fmt.Errorf("failed to write the test index: %w", err)
+	}
+	defer func() { _ = cmd.fw.Close() }()
+	if _, err = cmd.fw.Write(b); err != nil {
+		return fmt.Errorf("failed to write the test index: %w", err)
+	}
+	return nil
+}
+
+func (cmd *Cmd) loadTestIndex() (*testIndex, error) {
+	indexPath := path.Join(cmd.config.cacheDir(), "index.json")
+	b, err := cmd.readFile(indexPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("no per-test coverage found in %s, run `gocov test --per-test` first", cmd.config.cacheDir()) //nolint:goerr113
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", indexPath, err)
+	}
+	var index testIndex
+	if err = json.Unmarshal(b, &index); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", indexPath, err)
+	}
+	return &index, nil
+}
+
+// lineTests returns the names of the tests hitting the blocks touching each
+// line of the file.
+func (index *testIndex) lineTests(file *covFile) map[int][]string {
+	sets := map[int]map[string]bool{}
+	for _, report := range file.Reports {
+		for _, id := range index.Blocks[blockKey(file.Name, report)] {
+			if id < 0 || id >= len(index.Tests) {
+				continue
+			}
+			for line := report.StartLine; line <= report.EndLine; line++ {
+				if sets[line] == nil {
+					sets[line] = map[string]bool{}
+				}
+				sets[line][index.Tests[id].Name] = true
+			}
+		}
+	}
+	lines := map[int][]string{}
+	for line, set := range sets {
+		for name := range set {
+			lines[line] = append(lines[line], name)
+		}
+		sort.Strings(lines[line])
+	}
+	return lines
+}
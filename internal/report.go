@@ -2,54 +2,222 @@ package internal
 
 import (
 	"fmt"
-	"log"
+	"html/template"
+	"io"
+	"io/fs"
+	"path"
 	"strings"
 )
 
+const defaultHTMLOutput = "coverage.html"
+
 func (cmd *Cmd) Report(tree *Tree, stats Stats, args []string, files map[string]*covFile, moduleDir string) {
-	if cmd.config.HTMLOutput {
-		cmd.ReportHTML(tree, stats, args, files, moduleDir)
+	if !isValidSortKey(cmd.config.SortBy) {
+		_, _ = fmt.Fprintf(cmd.stderr, "invalid sort key: %s", cmd.config.SortBy)
+		cmd.exiter.Exit(1)
+		return
+	}
+	if !isValidStyle(cmd.config.Style) {
+		_, _ = fmt.Fprintf(cmd.stderr, "invalid table style: %s", cmd.config.Style)
+		cmd.exiter.Exit(1)
 		return
 	}
 
-	tree.Render(cmd.config, stats, args)
-}
+	if !cmd.config.Exported && !cmd.config.Dead {
+		if cmd.config.HTMLDir != "" {
+			cmd.ReportHTMLDir(tree)
+			return
+		}
+		if cmd.config.HTMLOutput {
+			cmd.ReportHTML(tree)
+			return
+		}
+	}
 
-func (cmd *Cmd) ReportHTML(tree *Tree, stats Stats, args []string, files map[string]*covFile, moduleDir string) {
-	var (
-		filesSourceBuilder strings.Builder
-		objectBuilder      strings.Builder
-		sb                 strings.Builder
-	)
+	closeOutput, err := cmd.openTextOutput(tree)
+	if err != nil {
+		_, _ = fmt.Fprint(cmd.stderr, err.Error())
+		cmd.exiter.Exit(1)
+		return
+	}
 
-	tree.RenderHTML(cmd, &filesSourceBuilder, &objectBuilder, cmd.config, stats, args, cmd.fsys, files, moduleDir)
+	switch {
+	case cmd.config.Exported:
+		cmd.ReportExported(files, moduleDir)
+	case cmd.config.Dead:
+		cmd.ReportDead(files, moduleDir)
+	default:
+		tree.Render(cmd.config, stats, args)
+	}
 
-	err := cmd.fw.Open("coverage.html")
+	if err = closeOutput(); err != nil {
+		_, _ = fmt.Fprint(cmd.stderr, err.Error())
+		cmd.exiter.Exit(1)
+	}
+}
+
+// openTextOutput points the text reports to the output file, if there is
+// one, and returns the function which closes it and points them back to
+// stdout. The file is written without colors and the table is not fitted
+// to the terminal.
+func (cmd *Cmd) openTextOutput(tree *Tree) (func() error, error) {
+	output := cmd.config.Output
+	if output == "" || output == "-" {
+		return func() error { return nil }, nil
+	}
+	if err := cmd.fw.Open(output); err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", output, err)
+	}
+	stdout := cmd.stdout
+	cmd.stdout, tree.writer = cmd.fw, cmd.fw
+	cmd.config.Color = false
+	cmd.config.Width = 0
+	return func() error {
+		cmd.stdout, tree.writer = stdout, stdout
+		if err := cmd.fw.Close(); err != nil {
+			return fmt.Errorf("failed to write %s: %w", output, err)
+		}
+		return nil
+	}, nil
+}
+
+func (cmd *Cmd) ReportHTML(tree *Tree) {
+	report, err := cmd.newHTMLReport(tree)
 	if err != nil {
-		log.Fatal(err)
+		_, _ = fmt.Fprint(cmd.stderr, err.Error())
+		cmd.exiter.Exit(1)
+		return
+	}
+	node, sources, err := tree.RenderHTML(cmd)
+	if err != nil {
+		_, _ = fmt.Fprintf(cmd.stderr, "failed to inspect file: %s", err.Error())
+		cmd.exiter.Exit(1)
+		return
+	}
+
+	output := cmd.config.Output
+	if output == "" {
+		output = defaultHTMLOutput
+	}
+	if output == "-" {
+		if err = report.write(cmd.stdout, node, sources, nil); err != nil {
+			_, _ = fmt.Fprintf(cmd.stderr, "failed to write the report: %s", err.Error())
+			cmd.exiter.Exit(1)
+		}
 		return
 	}
-	f := cmd.fw
 
-	sb.WriteString(`<script class="tree-data" type="application/json">`)
-	sb.WriteString(objectBuilder.String())
-	sb.WriteString("</script>")
+	if err = cmd.fw.Open(output); err != nil {
+		_, _ = fmt.Fprintf(cmd.stderr, "failed to open %s: %s", output, err.Error())
+		cmd.exiter.Exit(1)
+		return
+	}
+	err = report.write(cmd.fw, node, sources, nil)
+	if closeErr := cmd.fw.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_, _ = fmt.Fprintf(cmd.stderr, "failed to write %s: %s", output, err.Error())
+		cmd.exiter.Exit(1)
+	}
+}
 
-	tmpl := strings.ReplaceAll(tmpl, "<!-- REPORT -->", sb.String())
-	tmpl = strings.ReplaceAll(tmpl, "<!-- SCRIPT -->", Script)
-	tmpl = strings.ReplaceAll(tmpl, "<!-- SOURCE -->", filesSourceBuilder.String())
+// htmlReport holds the template of the pages of the html report and the
+// data which all of the pages share.
+type htmlReport struct {
+	template *template.Template
+	data     htmlData
+}
 
-	_, _ = fmt.Fprint(f, tmpl)
-	if err = f.Close(); err != nil {
-		log.Fatal(err)
+// newHTMLReport loads the template, the theme and the custom css of the
+// report, as set in the config.
+func (cmd *Cmd) newHTMLReport(tree *Tree) (*htmlReport, error) {
+	theme, ok := themes[cmd.config.Theme]
+	if !ok {
+		return nil, fmt.Errorf("invalid theme: %s", cmd.config.Theme)
 	}
+	css := "    " + theme + themeCSS
+	if cmd.config.HTML.CSS != "" {
+		custom, err := fs.ReadFile(cmd.fsys, cmd.config.HTML.CSS)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the html css: %w", err)
+		}
+		css += string(custom)
+	}
+
+	tmpl := defaultTemplate
+	if cmd.config.HTML.Template != "" {
+		text, err := fs.ReadFile(cmd.fsys, cmd.config.HTML.Template)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the html template: %w", err)
+		}
+		tmpl, err = template.New(path.Base(cmd.config.HTML.Template)).Parse(string(text))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse the html template: %w", err)
+		}
+	}
+
+	title := cmd.config.HTML.Title
+	if title == "" {
+		title = defaultHTMLTitle
+	}
+	root := tree.Root
+	return &htmlReport{
+		template: tmpl,
+		data: htmlData{
+			Title: title,
+			CSS:   template.CSS(css), //nolint:gosec
+			Stats: &htmlStats{
+				Statements:    root.allStatements,
+				Covered:       root.covered,
+				Percent:       htmlPercent(getPercent(root)),
+				Lines:         root.lines,
+				LinesCovered:  root.linesCovered,
+				LinesPercent:  htmlPercent(getLinesPercent(root)),
+				Branches:      root.branches,
+				BranchesTaken: root.branchesTaken,
+				BranchPercent: htmlPercent(getBranchPercent(root)),
+			},
+			Config: &htmlConfig{
+				Watermarks: htmlWatermarks{
+					High: htmlPercent(cmd.config.Watermarks.High),
+					Low:  htmlPercent(cmd.config.Watermarks.Low),
+				},
+			},
+			Script: template.JS(Script),
+		},
+	}, nil
+}
+
+// write executes the template for a page with the tree data and the sources
+// of the page, breadcrumbs are only given for the pages of a site.
+func (r *htmlReport) write(w io.Writer, node *htmlNode, sources []*htmlSource, breadcrumbs []*htmlCrumb) error {
+	data := r.data
+	config := *data.Config
+	config.Breadcrumbs = breadcrumbs
+	data.Tree, data.Sources, data.Config = node, sources, &config
+	return r.template.Execute(w, &data)
 }
 
-func escape(value string) string {
-	value = strings.ReplaceAll(value, "&", "&amp;")
-	value = strings.ReplaceAll(value, "<", "&lt;")
-	value = strings.ReplaceAll(value, ">", "&gt;")
-	value = strings.ReplaceAll(value, "&lt;/span&gt;", "</span>")
-	value = strings.ReplaceAll(value, "&lt;span style=\"background: pink\"&gt;", "<span style=\"background: pink\">")
-	return value
+// htmlSource renders the source of the file of the node, with its uncovered
+// statements highlighted.
+func (cmd *Cmd) htmlSource(n *Node) (*htmlSource, error) {
+	code, err := cmd.inspectFile(n.value, getPath(n.fullPath))
+	if err != nil {
+		return nil, err
+	}
+	// the source is escaped line by line by sourceLines
+	return &htmlSource{Path: n.fullPath, Code: template.HTML(code)}, nil //nolint:gosec
+}
+
+// textEscaper escapes the text content of an element, quotes only need to
+// be escaped in attributes.
+var textEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// escape escapes the line of source for the html report and only then
+// turns the markers of the uncovered statements into their tags.
+func escape(line string) string {
+	line = textEscaper.Replace(line)
+	line = strings.ReplaceAll(line, htmlUncoveredStart, uncoveredStartTag)
+	return strings.ReplaceAll(line, htmlUncoveredEnd, uncoveredEndTag)
 }
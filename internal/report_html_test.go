@@ -3,7 +3,9 @@ package internal_test
 
 import (
 	"bytes"
+	"encoding/json"
 	"io/fs"
+	"sort"
 	"strings"
 	"testing"
 	"testing/fstest"
@@ -25,31 +27,56 @@ example/internal/exec.go:26.29,28.2 1 0
 
 const expectedHTMLOutput = `<!doctype html>
 <head>
-<title>My coverage</title>
+<title>Coverage report</title>
 <style>
+    :root {
+        --background: white;
+        --text: black;
+        --link: rgb(0,0,238);
+        --border: #aaa;
+        --ok: rgb(233,245,212);
+        --ok-strong: rgb(94,145,53);
+        --warn: rgb(253,245,200);
+        --warn-strong: rgb(242,202,83);
+        --error: pink;
+        --error-strong: darkred;
+        --uncovered: pink;
+        --heat-1: rgb(222,235,247);
+        --heat-2: rgb(158,202,225);
+        --heat-3: rgb(253,174,107);
+        --heat-4: rgb(230,85,13);
+        --heat-text: white;
+    }
+    body { background: var(--background); color: var(--text); }
+    a { color: var(--link); text-decoration: none; }
     div { border: 1px solid transparent; }
-    .table { border: 1px solid #aaa; border-collapse: collapse; }
+    .table { border: 1px solid var(--border); border-collapse: collapse; }
     .source { display: none; }
     .visible { display: block; }
-    td { border: 1px solid #aaa; padding: 5px; }
-    a { text-decoration: none; }
-    .ok { background: rgb(233,245,212); }
-    .ok2 { background: rgb(94,145,53); }
-    .warn { background: rgb(253,245,200); }
-    .warn2 { background: rgb(242,202,83); }
-    .error { background: pink; }
+    td { border: 1px solid var(--border); padding: 5px; }
+    .ok { background: var(--ok); }
+    .ok2 { background: var(--ok-strong); }
+    .warn { background: var(--warn); }
+    .warn2 { background: var(--warn-strong); }
+    .error { background: var(--error); }
     .progress { width: 100px; height: 20px; }
     .progress > div { height: calc(20px - 1px); }
-    .ok .progress { border: 1px solid rgb(94,145,53); }
-    .ok .progress > div { background: rgb(94,145,53); }
-    .warn .progress { border: 1px solid rgb(242,202,83); }
-    .warn .progress > div { background: rgb(242,202,83); }
-    .error .progress { border: 1px solid darkred; }
-    .error .progress > div { background: darkred; }
+    .ok .progress { border: 1px solid var(--ok-strong); }
+    .ok .progress > div { background: var(--ok-strong); }
+    .warn .progress { border: 1px solid var(--warn-strong); }
+    .warn .progress > div { background: var(--warn-strong); }
+    .error .progress { border: 1px solid var(--error-strong); }
+    .error .progress > div { background: var(--error-strong); }
     .indicator { height: 10px; margin: 10px 0; }
-    .indicator.ok { background: rgb(94,145,53) }
-    .indicator.warn { background: rgb(242,202,83) }
-    .indicator.error { background: darkred }
+    .indicator.ok { background: var(--ok-strong) }
+    .indicator.warn { background: var(--warn-strong) }
+    .indicator.error { background: var(--error-strong) }
+    .uncovered { background: var(--uncovered); }
+    .heat-1 { background: var(--heat-1); }
+    .heat-2 { background: var(--heat-2); }
+    .heat-3 { background: var(--heat-3); }
+    .heat-4 { background: var(--heat-4); color: var(--heat-text); }
+    .tests { text-decoration: underline dotted; cursor: help; }
 </style>
 </head>
 <body>
@@ -57,12 +84,13 @@ const expectedHTMLOutput = `<!doctype html>
 <div class="stats"></div>
 <div class="indicator"></div>
 <table class="table"></table>
-<script class="tree-data" type="application/json">{"name":"example","all":10,"covered":4,"percent":40.00,"path":"example","level":0,"type":"directory","children":[{"name":"cmd","all":1,"covered":0,"percent":0.00,"path":"example/cmd","level":1,"type":"directory","children":[{"name":"exec.go","all":1,"covered":0,"percent":0.00,"path":"example/cmd/exec.go","level":2,"type":"file"}]},{"name":"internal","all":8,"covered":4,"percent":50.00,"path":"example/internal","level":1,"type":"directory","children":[{"name":"exec.go","all":8,"covered":4,"percent":50.00,"path":"example/internal/exec.go","level":2,"type":"file"}]},{"name":"main.go","all":1,"covered":0,"percent":0.00,"path":"example/main.go","level":1,"type":"file"}]}</script>
+<script class="tree-data" type="application/json">{"name":"example","all":10,"covered":4,"percent":40.00,"lines":22,"linesCovered":9,"linesPercent":40.91,"path":"example","level":0,"type":"directory","children":[{"name":"cmd","all":1,"covered":0,"percent":0.00,"lines":3,"linesCovered":0,"linesPercent":0.00,"path":"example/cmd","level":1,"type":"directory","children":[{"name":"exec.go","all":1,"covered":0,"percent":0.00,"lines":3,"linesCovered":0,"linesPercent":0.00,"path":"example/cmd/exec.go","level":2,"type":"file","uncoveredLines":"5-7"}]},{"name":"internal","all":8,"covered":4,"percent":50.00,"lines":16,"linesCovered":9,"linesPercent":56.25,"path":"example/internal","level":1,"type":"directory","children":[{"name":"exec.go","all":8,"covered":4,"percent":50.00,"lines":16,"linesCovered":9,"linesPercent":56.25,"path":"example/internal/exec.go","level":2,"type":"file","uncoveredLines":"15-17, 19, 26-28"}]},{"name":"main.go","all":1,"covered":0,"percent":0.00,"lines":3,"linesCovered":0,"linesPercent":0.00,"path":"example/main.go","level":1,"type":"file","uncoveredLines":"5-7"}]}</script>
+<script class="config-data" type="application/json">{"watermarks":{"high":80.00,"low":50.00}}</script>
 <div class="source" id="example/cmd/exec.go"><pre>1| package cmd
 2| 
 3| import "example/internal"
 4| 
-5| func Exec() <span style="background: pink">{
+5| func Exec() <span class="uncovered">{
 6| 	internal.Exec(1, 2, 3)
 7| }</span>
 8| 
@@ -81,18 +109,18 @@ const expectedHTMLOutput = `<!doctype html>
 12| 		return sum(a, b), nil
 13| 	}
 14| 
-15| 	<span style="background: pink">if op == 2 </span><span style="background: pink">{
+15| 	<span class="uncovered">if op == 2 </span><span class="uncovered">{
 16| 		return subtract(a, b), nil
 17| 	}</span>
 18| 
-19| 	<span style="background: pink">return 0, errors.New("unknown operation")</span>
+19| 	<span class="uncovered">return 0, errors.New("unknown operation")</span>
 20| }
 21| 
 22| func sum(a, b int) int {
 23| 	return a + b
 24| }
 25| 
-26| func subtract(a, b int) int <span style="background: pink">{
+26| func subtract(a, b int) int <span class="uncovered">{
 27| 	return a - b
 28| }</span>
 29| 
@@ -101,18 +129,82 @@ const expectedHTMLOutput = `<!doctype html>
 2| 
 3| import "example/cmd"
 4| 
-5| func main() <span style="background: pink">{
+5| func main() <span class="uncovered">{
 6| 	cmd.Exec()
 7| }</span>
 8| 
 </pre></div>
-
 <script>
 <!-- SCRIPT -->
 </script>
 </body>
 `
 
+var exampleHTMLFS = fstest.MapFS{
+	"go.mod":       {Data: []byte(`module github.com/slavsan/gospec`)},
+	"coverage.out": {Data: []byte(exampleCoverageOut6)},
+	"cmd/exec.go": {Data: []byte(strings.Join([]string{
+		`package cmd`,
+		``,
+		`import "example/internal"`,
+		``,
+		`func Exec() {`,
+		`	internal.Exec(1, 2, 3)`,
+		`}`,
+		``,
+	}, "\n"))},
+	"internal/exec.go": {Data: []byte(strings.Join([]string{
+		`package internal`,
+		``,
+		`import (`,
+		`	"errors"`,
+		`	"fmt"`,
+		`)`,
+		``,
+		`func Exec(op int, a, b int) (int, error) {`,
+		`	fmt.Printf("here...\n")`,
+		``,
+		`	if op == 1 {`,
+		`		return sum(a, b), nil`,
+		`	}`,
+		``,
+		`	if op == 2 {`,
+		`		return subtract(a, b), nil`,
+		`	}`,
+		``,
+		`	return 0, errors.New("unknown operation")`,
+		`}`,
+		``,
+		`func sum(a, b int) int {`,
+		`	return a + b`,
+		`}`,
+		``,
+		`func subtract(a, b int) int {`,
+		`	return a - b`,
+		`}`,
+		``,
+	}, "\n"))},
+	"main.go": {Data: []byte(strings.Join([]string{
+		`package main`,
+		``,
+		`import "example/cmd"`,
+		``,
+		`func main() {`,
+		`	cmd.Exec()`,
+		`}`,
+		``,
+	}, "\n"))},
+}
+
+// withFile returns a copy of the file system with one more file.
+func withFile(fsys fstest.MapFS, name, data string) fstest.MapFS {
+	files := fstest.MapFS{name: {Data: []byte(data)}}
+	for k, v := range fsys {
+		files[k] = v
+	}
+	return files
+}
+
 func TestStdoutReport2(t *testing.T) {
 	testCases := []struct {
 		title                    string
@@ -121,66 +213,14 @@ func TestStdoutReport2(t *testing.T) {
 		expectedStdout           string
 		expectedStderr           string
 		expectedFileWriterOutput string
+		expectedFileWriterPath   string
 		expectedExitCode         int
 		args                     []string
+		openErr                  error
 	}{
 		{
 			title: "with example coverage.out file and stdout report and colors disabled",
-			fsys: fstest.MapFS{
-				"go.mod":       {Data: []byte(`module github.com/slavsan/gospec`)},
-				"coverage.out": {Data: []byte(exampleCoverageOut6)},
-				"cmd/exec.go": {Data: []byte(strings.Join([]string{
-					`package cmd`,
-					``,
-					`import "example/internal"`,
-					``,
-					`func Exec() {`,
-					`	internal.Exec(1, 2, 3)`,
-					`}`,
-					``,
-				}, "\n"))},
-				"internal/exec.go": {Data: []byte(strings.Join([]string{
-					`package internal`,
-					``,
-					`import (`,
-					`	"errors"`,
-					`	"fmt"`,
-					`)`,
-					``,
-					`func Exec(op int, a, b int) (int, error) {`,
-					`	fmt.Printf("here...\n")`,
-					``,
-					`	if op == 1 {`,
-					`		return sum(a, b), nil`,
-					`	}`,
-					``,
-					`	if op == 2 {`,
-					`		return subtract(a, b), nil`,
-					`	}`,
-					``,
-					`	return 0, errors.New("unknown operation")`,
-					`}`,
-					``,
-					`func sum(a, b int) int {`,
-					`	return a + b`,
-					`}`,
-					``,
-					`func subtract(a, b int) int {`,
-					`	return a - b`,
-					`}`,
-					``,
-				}, "\n"))},
-				"main.go": {Data: []byte(strings.Join([]string{
-					`package main`,
-					``,
-					`import "example/cmd"`,
-					``,
-					`func main() {`,
-					`	cmd.Exec()`,
-					`}`,
-					``,
-				}, "\n"))},
-			},
+			fsys:  exampleHTMLFS,
 			config: &internal.Config{
 				Color:      false,
 				HTMLOutput: true,
@@ -191,6 +231,104 @@ func TestStdoutReport2(t *testing.T) {
 			expectedStderr:           "",
 			expectedExitCode:         0,
 			expectedFileWriterOutput: strings.ReplaceAll(expectedHTMLOutput, "<!-- SCRIPT -->", internal.Script),
+			expectedFileWriterPath:   "coverage.html",
+		},
+		{
+			title: "with the html report written to the output file",
+			fsys:  exampleHTMLFS,
+			config: &internal.Config{
+				HTMLOutput: true,
+				Output:     "artifacts/coverage.html",
+			},
+			expectedStdout:           "",
+			expectedStderr:           "",
+			expectedExitCode:         0,
+			expectedFileWriterOutput: strings.ReplaceAll(expectedHTMLOutput, "<!-- SCRIPT -->", internal.Script),
+			expectedFileWriterPath:   "artifacts/coverage.html",
+		},
+		{
+			title: "with the html report written to stdout",
+			fsys:  exampleHTMLFS,
+			config: &internal.Config{
+				HTMLOutput: true,
+				Output:     "-",
+			},
+			expectedStdout:           strings.ReplaceAll(expectedHTMLOutput, "<!-- SCRIPT -->", internal.Script),
+			expectedStderr:           "",
+			expectedExitCode:         0,
+			expectedFileWriterOutput: "",
+		},
+		{
+			title: "with the output file from the .gocov file",
+			fsys:  withFile(exampleHTMLFS, ".gocov", `{"output": "artifacts/index.html"}`),
+			config: &internal.Config{
+				HTMLOutput: true,
+			},
+			expectedStdout:           "",
+			expectedStderr:           "",
+			expectedExitCode:         0,
+			expectedFileWriterOutput: strings.ReplaceAll(expectedHTMLOutput, "<!-- SCRIPT -->", internal.Script),
+			expectedFileWriterPath:   "artifacts/index.html",
+		},
+		{
+			title: "with the table written to stdout despite the output of the .gocov file",
+			fsys:  withFile(exampleHTMLFS, ".gocov", `{"output": "artifacts/index.html"}`),
+			config: &internal.Config{
+				Color: false,
+			},
+			expectedStdout: strings.Join([]string{
+				`|-------------|--------|----------|------------|`,
+				`| File        |  Stmts |  % Stmts | Progress   |`,
+				`|-------------|--------|----------|------------|`,
+				`| example     |   4/10 |   40.00% | ■■■■       |`,
+				`|   cmd       |    0/1 |    0.00% |            |`,
+				`|     exec.go |    0/1 |    0.00% |            |`,
+				`|   internal  |    4/8 |   50.00% | ■■■■■      |`,
+				`|     exec.go |    4/8 |   50.00% | ■■■■■      |`,
+				`|   main.go   |    0/1 |    0.00% |            |`,
+				`|-------------|--------|----------|------------|`,
+				``,
+			}, "\n"),
+			expectedStderr:           "",
+			expectedExitCode:         0,
+			expectedFileWriterOutput: "",
+		},
+		{
+			title: "with the table written to the output file",
+			fsys:  exampleHTMLFS,
+			config: &internal.Config{
+				Color:  true,
+				Output: "coverage.txt",
+			},
+			expectedStdout:   "",
+			expectedStderr:   "",
+			expectedExitCode: 0,
+			expectedFileWriterOutput: strings.Join([]string{
+				`|-------------|--------|----------|------------|`,
+				`| File        |  Stmts |  % Stmts | Progress   |`,
+				`|-------------|--------|----------|------------|`,
+				`| example     |   4/10 |   40.00% | ■■■■       |`,
+				`|   cmd       |    0/1 |    0.00% |            |`,
+				`|     exec.go |    0/1 |    0.00% |            |`,
+				`|   internal  |    4/8 |   50.00% | ■■■■■      |`,
+				`|     exec.go |    4/8 |   50.00% | ■■■■■      |`,
+				`|   main.go   |    0/1 |    0.00% |            |`,
+				`|-------------|--------|----------|------------|`,
+				``,
+			}, "\n"),
+			expectedFileWriterPath: "coverage.txt",
+		},
+		{
+			title: "when the output file can not be opened",
+			fsys:  exampleHTMLFS,
+			config: &internal.Config{
+				HTMLOutput: true,
+			},
+			openErr:                  fs.ErrPermission,
+			expectedStdout:           "",
+			expectedStderr:           "failed to open coverage.html: permission denied",
+			expectedExitCode:         1,
+			expectedFileWriterOutput: "",
 		},
 	}
 
@@ -200,7 +338,7 @@ func TestStdoutReport2(t *testing.T) {
 			var (
 				stdout     bytes.Buffer
 				stderr     bytes.Buffer
-				fileWriter = &fileWriterMock{f: &bytes.Buffer{}}
+				fileWriter = &fileWriterMock{f: &bytes.Buffer{}, openErr: tc.openErr}
 			)
 			exiter := &exiterMock{}
 			internal.NewCommand(&stdout, &stderr, tc.fsys, tc.config, exiter, fileWriter).Exec(internal.Report, tc.args)
@@ -213,11 +351,14 @@ func TestStdoutReport2(t *testing.T) {
 			if tc.expectedExitCode != exiter.code {
 				t.Errorf("exit code does not match\n\texpected:\n`%d`\n\tactual:\n`%d`\n", tc.expectedExitCode, exiter.code)
 			}
+			if tc.expectedFileWriterPath != fileWriter.path {
+				t.Errorf("file path does not match\n\texpected: %s\n\tactual: %s\n", tc.expectedFileWriterPath, fileWriter.path)
+			}
 			if tc.expectedFileWriterOutput != fileWriter.f.(*bytes.Buffer).String() {
 				t.Errorf("table does not match\n\texpected:\n`%s`\n\tactual:\n`%s`\n", tc.expectedFileWriterOutput, fileWriter.f.(*bytes.Buffer).String())
 				linesLeft := strings.Split(tc.expectedFileWriterOutput, "\n")
 				linesRight := strings.Split(fileWriter.f.(*bytes.Buffer).String(), "\n")
-				for i := range linesLeft {
+				for i := 0; i < len(linesLeft) && i < len(linesRight); i++ {
 					if linesLeft[i] != linesRight[i] {
 						t.Errorf("line %d does not match\n\t left: '%s'\n\tright: '%s'\n",
 							i,
@@ -231,3 +372,406 @@ func TestStdoutReport2(t *testing.T) {
 		})
 	}
 }
+
+// siteWriterMock keeps the contents of every file written.
+type siteWriterMock struct {
+	files   map[string]*bytes.Buffer
+	current *bytes.Buffer
+}
+
+func (fw *siteWriterMock) Open(filepath string) error {
+	fw.current = &bytes.Buffer{}
+	fw.files[filepath] = fw.current
+	return nil
+}
+
+func (fw *siteWriterMock) Write(b []byte) (int, error) {
+	return fw.current.Write(b)
+}
+
+func (fw *siteWriterMock) Close() error {
+	return nil
+}
+
+func TestReportHTMLDir(t *testing.T) {
+	fsys := fstest.MapFS{
+		"go.mod":       {Data: []byte(`module github.com/slavsan/gospec`)},
+		"coverage.out": {Data: []byte(exampleCoverageOut6)},
+		"cmd/exec.go": {Data: []byte(strings.Join([]string{
+			`package cmd`,
+			``,
+			`import "example/internal"`,
+			``,
+			`func Exec() {`,
+			`	internal.Exec(1, 2, 3)`,
+			`}`,
+			``,
+		}, "\n"))},
+		"internal/exec.go": {Data: []byte(strings.Join([]string{
+			`package internal`,
+			``,
+			`import (`,
+			`	"errors"`,
+			`	"fmt"`,
+			`)`,
+			``,
+			`func Exec(op int, a, b int) (int, error) {`,
+			`	fmt.Printf("here...\n")`,
+			``,
+			`	if op == 1 {`,
+			`		return sum(a, b), nil`,
+			`	}`,
+			``,
+			`	if op == 2 {`,
+			`		return subtract(a, b), nil`,
+			`	}`,
+			``,
+			`	return 0, errors.New("unknown operation")`,
+			`}`,
+			``,
+			`func sum(a, b int) int {`,
+			`	return a + b`,
+			`}`,
+			``,
+			`func subtract(a, b int) int {`,
+			`	return a - b`,
+			`}`,
+			``,
+		}, "\n"))},
+		"main.go": {Data: []byte(strings.Join([]string{
+			`package main`,
+			``,
+			`import "example/cmd"`,
+			``,
+			`func main() {`,
+			`	cmd.Exec()`,
+			`}`,
+			``,
+		}, "\n"))},
+	}
+
+	var (
+		stdout     bytes.Buffer
+		stderr     bytes.Buffer
+		fileWriter = &siteWriterMock{files: map[string]*bytes.Buffer{}}
+		exiter     = &exiterMock{}
+	)
+	config := &internal.Config{HTMLOutput: true, HTMLDir: "out"}
+	internal.NewCommand(&stdout, &stderr, fsys, config, exiter, fileWriter).Exec(internal.Report, []string{})
+	if stderr.String() != "" || exiter.code != 0 {
+		t.Fatalf("unexpected failure: %s (exit code %d)", stderr.String(), exiter.code)
+	}
+
+	expectedPages := []string{
+		"out/example/cmd/exec.go.html",
+		"out/example/cmd/index.html",
+		"out/example/index.html",
+		"out/example/internal/exec.go.html",
+		"out/example/internal/index.html",
+		"out/example/main.go.html",
+		"out/index.html",
+	}
+	var pages []string
+	for name := range fileWriter.files {
+		pages = append(pages, name)
+	}
+	sort.Strings(pages)
+	if strings.Join(pages, "\n") != strings.Join(expectedPages, "\n") {
+		t.Fatalf("pages do not match\n\texpected:\n%s\n\tactual:\n%s\n", strings.Join(expectedPages, "\n"), strings.Join(pages, "\n"))
+	}
+
+	testCases := []struct {
+		page     string
+		expected []string
+	}{
+		{
+			page: "out/index.html",
+			expected: []string{
+				`<script class="tree-data" type="application/json">{"name":"All Files","all":10,"covered":4,"percent":40.00,` +
+					`"lines":22,"linesCovered":9,"linesPercent":40.91,"path":"","level":0,"type":"directory","children":[` +
+					`{"name":"example","all":10,"covered":4,"percent":40.00,"lines":22,"linesCovered":9,"linesPercent":40.91,` +
+					`"path":"example","level":0,"href":"example/index.html","type":"directory"}]}</script>`,
+				`<script class="config-data" type="application/json">{"watermarks":{"high":80.00,"low":50.00},` +
+					`"breadcrumbs":[{"name":"All Files"}]}</script>`,
+			},
+		},
+		{
+			page: "out/example/cmd/index.html",
+			expected: []string{
+				`"href":"exec.go.html","type":"file","uncoveredLines":"5-7"}]}</script>`,
+				`"breadcrumbs":[{"name":"All Files","href":"../../index.html"},{"name":"example","href":"../index.html"},{"name":"cmd"}]}`,
+			},
+		},
+		{
+			page: "out/example/main.go.html",
+			expected: []string{
+				`"path":"example/main.go","level":1,"type":"file","uncoveredLines":"5-7"}</script>`,
+				`"breadcrumbs":[{"name":"All Files","href":"../index.html"},{"name":"example","href":"index.html"},{"name":"main.go"}]}`,
+				`<div class="source" id="example/main.go"><pre>1| package main`,
+			},
+		},
+	}
+	for _, tc := range testCases {
+		page := fileWriter.files[tc.page].String()
+		for _, expected := range tc.expected {
+			if !strings.Contains(page, expected) {
+				t.Errorf("page %s does not contain\n`%s`\n\tactual:\n`%s`\n", tc.page, expected, page)
+			}
+		}
+	}
+
+	for name, page := range fileWriter.files {
+		sources := strings.Count(page.String(), `<div class="source"`)
+		if strings.HasSuffix(name, ".go.html") && sources != 1 || !strings.HasSuffix(name, ".go.html") && sources != 0 {
+			t.Errorf("page %s has %d sources", name, sources)
+		}
+	}
+}
+
+func TestReportHTMLDirEscaping(t *testing.T) {
+	const (
+		hostileDir  = `a#b?c%d`
+		hostileFile = `50%#1?.go`
+	)
+	fsys := fstest.MapFS{
+		"go.mod": {Data: []byte(`module github.com/slavsan/gospec`)},
+		"coverage.out": {Data: []byte(strings.Join([]string{
+			`mode: set`,
+			`example/` + hostileDir + `/` + hostileFile + `:3.13,5.2 1 0`,
+			``,
+		}, "\n"))},
+		hostileDir + "/" + hostileFile: {Data: []byte(strings.Join([]string{
+			`package hostile`,
+			``,
+			`func Exec() {`,
+			`	println()`,
+			`}`,
+			``,
+		}, "\n"))},
+	}
+
+	var (
+		stdout     bytes.Buffer
+		stderr     bytes.Buffer
+		fileWriter = &siteWriterMock{files: map[string]*bytes.Buffer{}}
+		exiter     = &exiterMock{}
+	)
+	config := &internal.Config{HTMLOutput: true, HTMLDir: "out"}
+	internal.NewCommand(&stdout, &stderr, fsys, config, exiter, fileWriter).Exec(internal.Report, []string{})
+	if stderr.String() != "" || exiter.code != 0 {
+		t.Fatalf("unexpected failure: %s (exit code %d)", stderr.String(), exiter.code)
+	}
+
+	testCases := []struct {
+		page     string
+		expected []string
+	}{
+		{
+			page:     "out/example/index.html",
+			expected: []string{`"href":"a%23b%3Fc%25d/index.html"`},
+		},
+		{
+			page:     "out/example/" + hostileDir + "/index.html",
+			expected: []string{`"href":"50%25%231%3F.go.html"`},
+		},
+	}
+	for _, tc := range testCases {
+		page, ok := fileWriter.files[tc.page]
+		if !ok {
+			t.Fatalf("page %s is not written", tc.page)
+		}
+		for _, expected := range tc.expected {
+			if !strings.Contains(page.String(), expected) {
+				t.Errorf("page %s does not contain\n`%s`\n\tactual:\n`%s`\n", tc.page, expected, page.String())
+			}
+		}
+	}
+}
+
+func TestReportHTMLEscaping(t *testing.T) {
+	const hostileDir = `q"uote\slash`
+	fsys := fstest.MapFS{
+		"go.mod": {Data: []byte(`module github.com/slavsan/gospec`)},
+		"coverage.out": {Data: []byte(strings.Join([]string{
+			`mode: set`,
+			`example/` + hostileDir + `/</script>.go:3.13,6.2 2 0`,
+			``,
+		}, "\n"))},
+		hostileDir + "/</script>.go": {Data: []byte(strings.Join([]string{
+			`package script`,
+			``,
+			`func Exec() {`,
+			`	println("</span>", "<span style=\"background: pink\">")`,
+			`	// </pre></div><script>alert(1)</script>`,
+			`}`,
+			``,
+		}, "\n"))},
+	}
+
+	var (
+		stdout     bytes.Buffer
+		stderr     bytes.Buffer
+		fileWriter = &fileWriterMock{f: &bytes.Buffer{}}
+		exiter     = &exiterMock{}
+	)
+	config := &internal.Config{HTMLOutput: true, Output: "-"}
+	internal.NewCommand(&stdout, &stderr, fsys, config, exiter, fileWriter).Exec(internal.Report, []string{})
+	if stderr.String() != "" || exiter.code != 0 {
+		t.Fatalf("unexpected failure: %s (exit code %d)", stderr.String(), exiter.code)
+	}
+	page := stdout.String()
+
+	const treeStart = `<script class="tree-data" type="application/json">`
+	data := page[strings.Index(page, treeStart)+len(treeStart):]
+	data = data[:strings.Index(data, "</script>")]
+	type node struct {
+		Name     string  `json:"name"`
+		Path     string  `json:"path"`
+		Children []*node `json:"children"`
+	}
+	var tree node
+	if err := json.Unmarshal([]byte(data), &tree); err != nil {
+		t.Fatalf("failed to parse the tree data: %s\n%s", err, data)
+	}
+	var names []string
+	for n := &tree; ; n = n.Children[0] {
+		names = append(names, n.Name)
+		if len(n.Children) == 0 {
+			if n.Path != "example/"+hostileDir+"/</script>.go" {
+				t.Errorf("file path does not match\n\texpected: %s\n\tactual: %s\n", "example/"+hostileDir+"/</script>.go", n.Path)
+			}
+			break
+		}
+	}
+	if strings.Join(names, "|") != "example|"+hostileDir+"|<|script>.go" {
+		t.Errorf("names do not match\n\texpected: %s\n\tactual: %s\n", "example|"+hostileDir+"|<|script>.go", strings.Join(names, "|"))
+	}
+
+	expected := strings.Join([]string{
+		`<div class="source" id="example/q&#34;uote\slash/&lt;/script&gt;.go"><pre>1| package script`,
+		`2| `,
+		`3| func Exec() <span class="uncovered">{`,
+		`4| 	println("&lt;/span&gt;", "&lt;span style=\"background: pink\"&gt;")`,
+		`5| 	// &lt;/pre&gt;&lt;/div&gt;&lt;script&gt;alert(1)&lt;/script&gt;`,
+		`6| }</span>`,
+		`7| `,
+		`</pre></div>`,
+	}, "\n")
+	if !strings.Contains(page, expected) {
+		t.Errorf("source does not match\n\texpected:\n%s\n\tactual:\n%s\n", expected, page)
+	}
+	if count := strings.Count(page, "</script>"); count != 3 {
+		t.Errorf("expected the page to close 3 scripts, but it closes %d", count)
+	}
+}
+
+func TestReportHTMLTemplate(t *testing.T) {
+	testCases := []struct {
+		title            string
+		fsys             fstest.MapFS
+		config           *internal.Config
+		expected         []string
+		expectedStderr   string
+		expectedExitCode int
+	}{
+		{
+			title:  "with the dark theme",
+			fsys:   exampleHTMLFS,
+			config: &internal.Config{Theme: internal.ThemeDark},
+			expected: []string{
+				"<title>Coverage report</title>",
+				"        --background: rgb(30,30,30);\n",
+				"    .uncovered { background: var(--uncovered); }\n",
+			},
+		},
+		{
+			title: "with the theme, the title and the css from the .gocov file",
+			fsys: withFile(
+				withFile(exampleHTMLFS, "docs/coverage.css", ".table { width: 100%; }\n"),
+				".gocov", `{"html": {"title": "gospec coverage", "theme": "high-contrast", "css": "docs/coverage.css"}}`,
+			),
+			config: &internal.Config{},
+			expected: []string{
+				"<title>gospec coverage</title>",
+				"        --background: black;\n",
+				"    .tests { text-decoration: underline dotted; cursor: help; }\n.table { width: 100%; }\n</style>",
+			},
+		},
+		{
+			title:  "with the theme from the flag over the .gocov file",
+			fsys:   withFile(exampleHTMLFS, ".gocov", `{"html": {"theme": "high-contrast"}}`),
+			config: &internal.Config{Theme: internal.ThemeLight},
+			expected: []string{
+				"        --background: white;\n",
+			},
+		},
+		{
+			title: "with a custom template",
+			fsys: withFile(exampleHTMLFS, "docs/coverage.tmpl", strings.Join([]string{
+				`<h1>{{.Title}}: {{.Stats.Covered}}/{{.Stats.Statements}} ({{.Stats.Percent}}%)</h1>`,
+				`{{range .Tree.Children}}<p>{{.Name}} {{.Percent}}%</p>{{end}}`,
+				`{{range .Sources}}<p>{{.Path}}</p>{{end}}`,
+				`<script>const high = {{.Config.Watermarks.High}}</script>`,
+				``,
+			}, "\n")),
+			config: &internal.Config{HTML: internal.HTMLConfig{Template: "docs/coverage.tmpl", Title: "<gospec>"}},
+			expected: []string{
+				strings.Join([]string{
+					`<h1>&lt;gospec&gt;: 4/10 (40.00%)</h1>`,
+					`<p>cmd 0.00%</p><p>internal 50.00%</p><p>main.go 0.00%</p>`,
+					`<p>example/cmd/exec.go</p><p>example/internal/exec.go</p><p>example/main.go</p>`,
+					`<script>const high =  80.00 </script>`,
+					``,
+				}, "\n"),
+			},
+		},
+		{
+			title:            "with an unknown theme",
+			fsys:             exampleHTMLFS,
+			config:           &internal.Config{Theme: "solarized"},
+			expectedStderr:   "invalid theme: solarized",
+			expectedExitCode: 1,
+		},
+		{
+			title:            "with a missing template",
+			fsys:             exampleHTMLFS,
+			config:           &internal.Config{HTML: internal.HTMLConfig{Template: "docs/coverage.tmpl"}},
+			expectedStderr:   "failed to read the html template: open docs/coverage.tmpl: file does not exist",
+			expectedExitCode: 1,
+		},
+		{
+			title:            "with an invalid template",
+			fsys:             withFile(exampleHTMLFS, "docs/coverage.tmpl", `{{.Title}}{{end}}`),
+			config:           &internal.Config{HTML: internal.HTMLConfig{Template: "docs/coverage.tmpl"}},
+			expectedStderr:   "failed to parse the html template: template: coverage.tmpl:1: unexpected {{end}}",
+			expectedExitCode: 1,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.title, func(t *testing.T) {
+			var (
+				stdout     bytes.Buffer
+				stderr     bytes.Buffer
+				fileWriter = &fileWriterMock{f: &bytes.Buffer{}}
+				exiter     = &exiterMock{}
+			)
+			tc.config.HTMLOutput = true
+			tc.config.Output = "-"
+			internal.NewCommand(&stdout, &stderr, tc.fsys, tc.config, exiter, fileWriter).Exec(internal.Report, []string{})
+
+			if tc.expectedStderr != stderr.String() {
+				t.Errorf("stderr does not match\n\texpected: %s\n\tactual: %s\n", tc.expectedStderr, stderr.String())
+			}
+			if tc.expectedExitCode != exiter.code {
+				t.Errorf("expected exit code %d but got %d", tc.expectedExitCode, exiter.code)
+			}
+			for _, expected := range tc.expected {
+				if !strings.Contains(stdout.String(), expected) {
+					t.Errorf("page does not contain\n%s\n\tactual:\n%s\n", expected, stdout.String())
+				}
+			}
+		})
+	}
+}
@@ -11,6 +11,10 @@ import (
 	"github.com/slavsan/gocov/internal"
 )
 
+func percentFilter(v float64) *float64 {
+	return &v
+}
+
 func TestStdoutReport(t *testing.T) { //nolint:maintidx
 	testCases := []struct {
 		title            string
@@ -175,6 +179,28 @@ func TestStdoutReport(t *testing.T) { //nolint:maintidx
 			expectedStderr:   "",
 			expectedExitCode: 0,
 		},
+		{
+			title: "with the depth flag provided and a flat file list",
+			fsys: fstest.MapFS{
+				"go.mod":       {Data: []byte(`module github.com/slavsan/gocov`)},
+				"coverage.out": {Data: []byte(exampleCoverageOut2)},
+			},
+			config: &internal.Config{
+				Color: false,
+				Flat:  true,
+				Depth: 1,
+			},
+			expectedStdout: strings.Join([]string{
+				`|-----------|---------|----------|------------|`,
+				`| File      |   Stmts |  % Stmts | Progress   |`,
+				`|-----------|---------|----------|------------|`,
+				`| main.go:5 |     0/1 |    0.00% |            |`,
+				`|-----------|---------|----------|------------|`,
+				``,
+			}, "\n"),
+			expectedStderr:   "",
+			expectedExitCode: 0,
+		},
 		{
 			title: "with invalid coverage.out file, invalid column value",
 			fsys: fstest.MapFS{
@@ -363,6 +389,758 @@ func TestStdoutReport(t *testing.T) { //nolint:maintidx
 			expectedStderr:   "",
 			expectedExitCode: 0,
 		},
+		{
+			title: "sorted by percent",
+			fsys: fstest.MapFS{
+				"go.mod":       {Data: []byte(`module github.com/slavsan/gospec`)},
+				"coverage.out": {Data: []byte(exampleCoverageOut)},
+			},
+			config: &internal.Config{
+				Color:  false,
+				SortBy: internal.SortByPercent,
+			},
+			expectedStdout: strings.Join([]string{
+				`|------------------|---------|----------|------------|`,
+				`| File             |   Stmts |  % Stmts | Progress   |`,
+				`|------------------|---------|----------|------------|`,
+				`| gospec           | 237/323 |   73.37% | ■■■■■■■    |`,
+				`|   cmd            |    0/28 |    0.00% |            |`,
+				`|     cover.go     |    0/28 |    0.00% |            |`,
+				`|   expect.go      |   43/86 |   50.00% | ■■■■■      |`,
+				`|   featurespec.go |  92/107 |   85.98% | ■■■■■■■■   |`,
+				`|   gospec.go      | 102/102 |  100.00% | ■■■■■■■■■■ |`,
+				`|------------------|---------|----------|------------|`,
+				``,
+			}, "\n"),
+			expectedStderr:   "",
+			expectedExitCode: 0,
+		},
+		{
+			title: "sorted by statements in reverse",
+			fsys: fstest.MapFS{
+				"go.mod":       {Data: []byte(`module github.com/slavsan/gospec`)},
+				"coverage.out": {Data: []byte(exampleCoverageOut)},
+			},
+			config: &internal.Config{
+				Color:   false,
+				SortBy:  internal.SortByStmts,
+				Reverse: true,
+			},
+			expectedStdout: strings.Join([]string{
+				`|------------------|---------|----------|------------|`,
+				`| File             |   Stmts |  % Stmts | Progress   |`,
+				`|------------------|---------|----------|------------|`,
+				`| gospec           | 237/323 |   73.37% | ■■■■■■■    |`,
+				`|   cmd            |    0/28 |    0.00% |            |`,
+				`|     cover.go     |    0/28 |    0.00% |            |`,
+				`|   expect.go      |   43/86 |   50.00% | ■■■■■      |`,
+				`|   gospec.go      | 102/102 |  100.00% | ■■■■■■■■■■ |`,
+				`|   featurespec.go |  92/107 |   85.98% | ■■■■■■■■   |`,
+				`|------------------|---------|----------|------------|`,
+				``,
+			}, "\n"),
+			expectedStderr:   "",
+			expectedExitCode: 0,
+		},
+		{
+			title: "with only uncovered files",
+			fsys: fstest.MapFS{
+				"go.mod":       {Data: []byte(`module github.com/slavsan/gospec`)},
+				"coverage.out": {Data: []byte(exampleCoverageOut)},
+			},
+			config: &internal.Config{
+				Color:         false,
+				OnlyUncovered: true,
+			},
+			expectedStdout: strings.Join([]string{
+				`|------------------|---------|----------|------------|`,
+				`| File             |   Stmts |  % Stmts | Progress   |`,
+				`|------------------|---------|----------|------------|`,
+				`| gospec           | 237/323 |   73.37% | ■■■■■■■    |`,
+				`|   cmd            |    0/28 |    0.00% |            |`,
+				`|     cover.go     |    0/28 |    0.00% |            |`,
+				`|   expect.go      |   43/86 |   50.00% | ■■■■■      |`,
+				`|   featurespec.go |  92/107 |   85.98% | ■■■■■■■■   |`,
+				`|------------------|---------|----------|------------|`,
+				``,
+			}, "\n"),
+			expectedStderr:   "",
+			expectedExitCode: 0,
+		},
+		{
+			title: "with min and max percent filters",
+			fsys: fstest.MapFS{
+				"go.mod":       {Data: []byte(`module github.com/slavsan/gospec`)},
+				"coverage.out": {Data: []byte(exampleCoverageOut)},
+			},
+			config: &internal.Config{
+				Color:      false,
+				MinPercent: percentFilter(40),
+				MaxPercent: percentFilter(90),
+			},
+			expectedStdout: strings.Join([]string{
+				`|------------------|---------|----------|------------|`,
+				`| File             |   Stmts |  % Stmts | Progress   |`,
+				`|------------------|---------|----------|------------|`,
+				`| gospec           | 237/323 |   73.37% | ■■■■■■■    |`,
+				`|   expect.go      |   43/86 |   50.00% | ■■■■■      |`,
+				`|   featurespec.go |  92/107 |   85.98% | ■■■■■■■■   |`,
+				`|------------------|---------|----------|------------|`,
+				``,
+			}, "\n"),
+			expectedStderr:   "",
+			expectedExitCode: 0,
+		},
+		{
+			title: "with a max percent filter of zero",
+			fsys: fstest.MapFS{
+				"go.mod":       {Data: []byte(`module github.com/slavsan/gospec`)},
+				"coverage.out": {Data: []byte(exampleCoverageOut)},
+			},
+			config: &internal.Config{
+				Color:      false,
+				MaxPercent: percentFilter(0),
+			},
+			expectedStdout: strings.Join([]string{
+				`|------------------|---------|----------|------------|`,
+				`| File             |   Stmts |  % Stmts | Progress   |`,
+				`|------------------|---------|----------|------------|`,
+				`| gospec           | 237/323 |   73.37% | ■■■■■■■    |`,
+				`|   cmd            |    0/28 |    0.00% |            |`,
+				`|     cover.go     |    0/28 |    0.00% |            |`,
+				`|------------------|---------|----------|------------|`,
+				``,
+			}, "\n"),
+			expectedStderr:   "",
+			expectedExitCode: 0,
+		},
+		{
+			title: "with the top files by uncovered statements",
+			fsys: fstest.MapFS{
+				"go.mod":       {Data: []byte(`module github.com/slavsan/gospec`)},
+				"coverage.out": {Data: []byte(exampleCoverageOut)},
+			},
+			config: &internal.Config{
+				Color:  false,
+				SortBy: internal.SortByUncovered,
+				Top:    2,
+			},
+			expectedStdout: strings.Join([]string{
+				`|------------------|---------|----------|------------|`,
+				`| File             |   Stmts |  % Stmts | Progress   |`,
+				`|------------------|---------|----------|------------|`,
+				`| gospec           | 237/323 |   73.37% | ■■■■■■■    |`,
+				`|   expect.go      |   43/86 |   50.00% | ■■■■■      |`,
+				`|   cmd            |    0/28 |    0.00% |            |`,
+				`|     cover.go     |    0/28 |    0.00% |            |`,
+				`|------------------|---------|----------|------------|`,
+				``,
+			}, "\n"),
+			expectedStderr:   "",
+			expectedExitCode: 0,
+		},
+		{
+			title: "with the top files of the selected path",
+			fsys: fstest.MapFS{
+				"go.mod":       {Data: []byte(`module github.com/slavsan/gospec`)},
+				"coverage.out": {Data: []byte(exampleCoverageOut)},
+			},
+			args: []string{"gospec/featurespec.go"},
+			config: &internal.Config{
+				Color:  false,
+				SortBy: internal.SortByUncovered,
+				Top:    1,
+			},
+			expectedStdout: strings.Join([]string{
+				`|------------------|---------|----------|------------|`,
+				`| File             |   Stmts |  % Stmts | Progress   |`,
+				`|------------------|---------|----------|------------|`,
+				`| gospec           | 237/323 |   73.37% | ■■■■■■■    |`,
+				`|   featurespec.go |  92/107 |   85.98% | ■■■■■■■■   |`,
+				`|------------------|---------|----------|------------|`,
+				``,
+			}, "\n"),
+			expectedStderr:   "",
+			expectedExitCode: 0,
+		},
+		{
+			title: "with the top files of the selected path in a flat file list",
+			fsys: fstest.MapFS{
+				"go.mod":       {Data: []byte(`module github.com/slavsan/gospec`)},
+				"coverage.out": {Data: []byte(exampleCoverageOut)},
+			},
+			args: []string{"gospec/featurespec.go"},
+			config: &internal.Config{
+				Color:  false,
+				Flat:   true,
+				SortBy: internal.SortByPercent,
+				Top:    1,
+			},
+			expectedStdout: strings.Join([]string{
+				`|--------------------|---------|----------|------------|`,
+				`| File               |   Stmts |  % Stmts | Progress   |`,
+				`|--------------------|---------|----------|------------|`,
+				`| featurespec.go:119 |  92/107 |   85.98% | ■■■■■■■■   |`,
+				`|--------------------|---------|----------|------------|`,
+				``,
+			}, "\n"),
+			expectedStderr:   "",
+			expectedExitCode: 0,
+		},
+		{
+			title: "with flat file list",
+			fsys: fstest.MapFS{
+				"go.mod":       {Data: []byte(`module github.com/slavsan/gospec`)},
+				"coverage.out": {Data: []byte(exampleCoverageOut)},
+			},
+			config: &internal.Config{
+				Color: false,
+				Flat:  true,
+			},
+			expectedStdout: strings.Join([]string{
+				`|--------------------|---------|----------|------------|`,
+				`| File               |   Stmts |  % Stmts | Progress   |`,
+				`|--------------------|---------|----------|------------|`,
+				`| cmd/cover.go:11    |    0/28 |    0.00% |            |`,
+				`| expect.go:135      |   43/86 |   50.00% | ■■■■■      |`,
+				`| featurespec.go:119 |  92/107 |   85.98% | ■■■■■■■■   |`,
+				`| gospec.go:1        | 102/102 |  100.00% | ■■■■■■■■■■ |`,
+				`|--------------------|---------|----------|------------|`,
+				``,
+			}, "\n"),
+			expectedStderr:   "",
+			expectedExitCode: 0,
+		},
+		{
+			title: "with flat file list sorted by percent and filtered",
+			fsys: fstest.MapFS{
+				"go.mod":       {Data: []byte(`module github.com/slavsan/gospec`)},
+				"coverage.out": {Data: []byte(exampleCoverageOut)},
+			},
+			config: &internal.Config{
+				Color:         false,
+				Flat:          true,
+				SortBy:        internal.SortByPercent,
+				Reverse:       true,
+				OnlyUncovered: true,
+			},
+			expectedStdout: strings.Join([]string{
+				`|--------------------|---------|----------|------------|`,
+				`| File               |   Stmts |  % Stmts | Progress   |`,
+				`|--------------------|---------|----------|------------|`,
+				`| featurespec.go:119 |  92/107 |   85.98% | ■■■■■■■■   |`,
+				`| expect.go:135      |   43/86 |   50.00% | ■■■■■      |`,
+				`| cmd/cover.go:11    |    0/28 |    0.00% |            |`,
+				`|--------------------|---------|----------|------------|`,
+				``,
+			}, "\n"),
+			expectedStderr:   "",
+			expectedExitCode: 0,
+		},
+		{
+			title: "with single child directories",
+			fsys: fstest.MapFS{
+				"go.mod":       {Data: []byte(`module github.com/slavsan/gocov`)},
+				"coverage.out": {Data: []byte(exampleCoverageOut7)},
+			},
+			config: &internal.Config{
+				Color: false,
+			},
+			expectedStdout: strings.Join([]string{
+				`|-----------------------|--------|----------|------------|`,
+				`| File                  |  Stmts |  % Stmts | Progress   |`,
+				`|-----------------------|--------|----------|------------|`,
+				`| gocov                 |    6/8 |   75.00% | ■■■■■■■    |`,
+				`|   main.go             |    1/1 |  100.00% | ■■■■■■■■■■ |`,
+				`|   pkg                 |    5/7 |   71.43% | ■■■■■■■    |`,
+				`|     api               |    5/7 |   71.43% | ■■■■■■■    |`,
+				`|       v1              |    5/7 |   71.43% | ■■■■■■■    |`,
+				`|         internal      |    5/6 |   83.33% | ■■■■■■■■   |`,
+				`|           handlers    |    5/6 |   83.33% | ■■■■■■■■   |`,
+				`|             orders.go |    3/3 |  100.00% | ■■■■■■■■■■ |`,
+				`|             users.go  |    2/3 |   66.67% | ■■■■■■     |`,
+				`|         models        |    0/1 |    0.00% |            |`,
+				`|           order.go    |    0/1 |    0.00% |            |`,
+				`|-----------------------|--------|----------|------------|`,
+				``,
+			}, "\n"),
+			expectedStderr:   "",
+			expectedExitCode: 0,
+		},
+		{
+			title: "with compacted single child directories",
+			fsys: fstest.MapFS{
+				"go.mod":       {Data: []byte(`module github.com/slavsan/gocov`)},
+				"coverage.out": {Data: []byte(exampleCoverageOut7)},
+			},
+			config: &internal.Config{
+				Color:        false,
+				Compact:      true,
+				WithFullPath: true,
+			},
+			expectedStdout: strings.Join([]string{
+				`|-----------------------|--------|----------|------------|----------------------------------------------|`,
+				`| File                  |  Stmts |  % Stmts | Progress   | Full path                                    |`,
+				`|-----------------------|--------|----------|------------|----------------------------------------------|`,
+				`| gocov                 |    6/8 |   75.00% | ■■■■■■■    | gocov                                        |`,
+				`|   main.go             |    1/1 |  100.00% | ■■■■■■■■■■ | gocov/main.go                                |`,
+				`|   pkg/api/v1          |    5/7 |   71.43% | ■■■■■■■    | gocov/pkg/api/v1                             |`,
+				`|     internal/handlers |    5/6 |   83.33% | ■■■■■■■■   | gocov/pkg/api/v1/internal/handlers           |`,
+				`|       orders.go       |    3/3 |  100.00% | ■■■■■■■■■■ | gocov/pkg/api/v1/internal/handlers/orders.go |`,
+				`|       users.go        |    2/3 |   66.67% | ■■■■■■     | gocov/pkg/api/v1/internal/handlers/users.go  |`,
+				`|     models            |    0/1 |    0.00% |            | gocov/pkg/api/v1/models                      |`,
+				`|       order.go        |    0/1 |    0.00% |            | gocov/pkg/api/v1/models/order.go             |`,
+				`|-----------------------|--------|----------|------------|----------------------------------------------|`,
+				``,
+			}, "\n"),
+			expectedStderr:   "",
+			expectedExitCode: 0,
+		},
+		{
+			title: "with unicode table style",
+			fsys: fstest.MapFS{
+				"go.mod":       {Data: []byte(`module github.com/slavsan/gocov`)},
+				"coverage.out": {Data: []byte(exampleCoverageOut3)},
+			},
+			config: &internal.Config{
+				Color: false,
+				Style: internal.StyleUnicode,
+			},
+			expectedStdout: strings.Join([]string{
+				`┌──────────────┬────────┬──────────┬────────────┐`,
+				`│ File         │  Stmts │  % Stmts │ Progress   │`,
+				`├──────────────┼────────┼──────────┼────────────┤`,
+				`│ gocov        │   4/15 │   26.67% │ ■■         │`,
+				`│   cmd        │   0/11 │    0.00% │            │`,
+				`│     gocov.go │   0/11 │    0.00% │            │`,
+				`│   internal   │    4/4 │  100.00% │ ■■■■■■■■■■ │`,
+				`│     gocov.go │    4/4 │  100.00% │ ■■■■■■■■■■ │`,
+				`└──────────────┴────────┴──────────┴────────────┘`,
+				``,
+			}, "\n"),
+			expectedStderr:   "",
+			expectedExitCode: 0,
+		},
+		{
+			title: "with rounded table style",
+			fsys: fstest.MapFS{
+				"go.mod":       {Data: []byte(`module github.com/slavsan/gocov`)},
+				"coverage.out": {Data: []byte(exampleCoverageOut3)},
+			},
+			config: &internal.Config{
+				Color: false,
+				Style: internal.StyleRounded,
+				Depth: 1,
+			},
+			expectedStdout: strings.Join([]string{
+				`╭──────────────┬────────┬──────────┬────────────╮`,
+				`│ File         │  Stmts │  % Stmts │ Progress   │`,
+				`├──────────────┼────────┼──────────┼────────────┤`,
+				`│ gocov        │   4/15 │   26.67% │ ■■         │`,
+				`│   cmd        │   0/11 │    0.00% │            │`,
+				`│   internal   │    4/4 │  100.00% │ ■■■■■■■■■■ │`,
+				`╰──────────────┴────────┴──────────┴────────────╯`,
+				``,
+			}, "\n"),
+			expectedStderr:   "",
+			expectedExitCode: 0,
+		},
+		{
+			title: "without table borders and with a custom progress bar",
+			fsys: fstest.MapFS{
+				"go.mod":       {Data: []byte(`module github.com/slavsan/gocov`)},
+				"coverage.out": {Data: []byte(exampleCoverageOut3)},
+			},
+			config: &internal.Config{
+				Color:     false,
+				Style:     internal.StyleNone,
+				BarWidth:  12,
+				BarSymbol: "#",
+			},
+			expectedStdout: strings.Join([]string{
+				` File           Stmts   % Stmts  Progress     `,
+				` gocov           4/15    26.67%  ###          `,
+				`   cmd           0/11     0.00%               `,
+				`     gocov.go    0/11     0.00%               `,
+				`   internal       4/4   100.00%  ############ `,
+				`     gocov.go     4/4   100.00%  ############ `,
+				``,
+			}, "\n"),
+			expectedStderr:   "",
+			expectedExitCode: 0,
+		},
+		{
+			title: "with table wider than the terminal",
+			fsys: fstest.MapFS{
+				"go.mod":       {Data: []byte(`module github.com/slavsan/gocov`)},
+				"coverage.out": {Data: []byte(exampleCoverageOut7)},
+			},
+			config: &internal.Config{
+				Color:        false,
+				WithFullPath: true,
+				Width:        70,
+			},
+			expectedStdout: strings.Join([]string{
+				`|-----------------------|--------|----------|------------|-----------|`,
+				`| File                  |  Stmts |  % Stmts | Progress   | Full path |`,
+				`|-----------------------|--------|----------|------------|-----------|`,
+				`| gocov                 |    6/8 |   75.00% | ■■■■■■■    | gocov     |`,
+				`|   main.go             |    1/1 |  100.00% | ■■■■■■■■■■ | goc....go |`,
+				`|   pkg                 |    5/7 |   71.43% | ■■■■■■■    | gocov/pkg |`,
+				`|     api               |    5/7 |   71.43% | ■■■■■■■    | goc...api |`,
+				`|       v1              |    5/7 |   71.43% | ■■■■■■■    | goc.../v1 |`,
+				`|         internal      |    5/6 |   83.33% | ■■■■■■■■   | goc...nal |`,
+				`|           handlers    |    5/6 |   83.33% | ■■■■■■■■   | goc...ers |`,
+				`|             orders.go |    3/3 |  100.00% | ■■■■■■■■■■ | goc....go |`,
+				`|             users.go  |    2/3 |   66.67% | ■■■■■■     | goc....go |`,
+				`|         models        |    0/1 |    0.00% |            | goc...els |`,
+				`|           order.go    |    0/1 |    0.00% |            | goc....go |`,
+				`|-----------------------|--------|----------|------------|-----------|`,
+				``,
+			}, "\n"),
+			expectedStderr:   "",
+			expectedExitCode: 0,
+		},
+		{
+			title: "with flat file list narrower than the paths",
+			fsys: fstest.MapFS{
+				"go.mod":       {Data: []byte(`module github.com/slavsan/gocov`)},
+				"coverage.out": {Data: []byte(exampleCoverageOut7)},
+			},
+			config: &internal.Config{
+				Color: false,
+				Flat:  true,
+				Width: 60,
+			},
+			expectedStdout: strings.Join([]string{
+				`|-------------------------|--------|----------|------------|`,
+				`| File                    |  Stmts |  % Stmts | Progress   |`,
+				`|-------------------------|--------|----------|------------|`,
+				`| main.go:1               |    1/1 |  100.00% | ■■■■■■■■■■ |`,
+				`| pkg/api/v1...order.go:3 |    0/1 |    0.00% |            |`,
+				`| pkg/api/v1...rders.go:1 |    3/3 |  100.00% | ■■■■■■■■■■ |`,
+				`| pkg/api/v1...sers.go:11 |    2/3 |   66.67% | ■■■■■■     |`,
+				`|-------------------------|--------|----------|------------|`,
+				``,
+			}, "\n"),
+			expectedStderr:   "",
+			expectedExitCode: 0,
+		},
+		{
+			title: "with uncovered line ranges",
+			fsys: fstest.MapFS{
+				"go.mod":       {Data: []byte(`module github.com/slavsan/gocov`)},
+				"coverage.out": {Data: []byte(exampleCoverageOut6)},
+			},
+			config: &internal.Config{
+				Color:          false,
+				UncoveredLines: true,
+			},
+			expectedStdout: strings.Join([]string{
+				`|-------------|--------|----------|------------|-------------------|`,
+				`| File        |  Stmts |  % Stmts | Progress   | Uncovered Line #s |`,
+				`|-------------|--------|----------|------------|-------------------|`,
+				`| example     |   4/10 |   40.00% | ■■■■       |                   |`,
+				`|   cmd       |    0/1 |    0.00% |            |                   |`,
+				`|     exec.go |    0/1 |    0.00% |            | 5-7               |`,
+				`|   internal  |    4/8 |   50.00% | ■■■■■      |                   |`,
+				`|     exec.go |    4/8 |   50.00% | ■■■■■      | 15-17, 19, 26-28  |`,
+				`|   main.go   |    0/1 |    0.00% |            | 5-7               |`,
+				`|-------------|--------|----------|------------|-------------------|`,
+				``,
+			}, "\n"),
+			expectedStderr:   "",
+			expectedExitCode: 0,
+		},
+		{
+			title: "with line coverage",
+			fsys: fstest.MapFS{
+				"go.mod":       {Data: []byte(`module github.com/slavsan/gocov`)},
+				"coverage.out": {Data: []byte(exampleCoverageOut6)},
+			},
+			config: &internal.Config{
+				Color: false,
+				Lines: true,
+			},
+			expectedStdout: strings.Join([]string{
+				`|-------------|--------|----------|----------|------------|`,
+				`| File        |  Stmts |  % Stmts |  % Lines | Progress   |`,
+				`|-------------|--------|----------|----------|------------|`,
+				`| example     |   4/10 |   40.00% |   40.91% | ■■■■       |`,
+				`|   cmd       |    0/1 |    0.00% |    0.00% |            |`,
+				`|     exec.go |    0/1 |    0.00% |    0.00% |            |`,
+				`|   internal  |    4/8 |   50.00% |   56.25% | ■■■■■      |`,
+				`|     exec.go |    4/8 |   50.00% |   56.25% | ■■■■■      |`,
+				`|   main.go   |    0/1 |    0.00% |    0.00% |            |`,
+				`|-------------|--------|----------|----------|------------|`,
+				``,
+			}, "\n"),
+			expectedStderr:   "",
+			expectedExitCode: 0,
+		},
+		{
+			title: "with branch coverage",
+			fsys: fstest.MapFS{
+				"go.mod":       {Data: []byte(`module github.com/slavsan/gocov`)},
+				"coverage.out": {Data: []byte(exampleBranchCoverageOut)},
+				"main.go":      {Data: []byte(exampleCountSourceFile)},
+				"cmd/kind.go":  {Data: []byte(exampleBranchSourceFile)},
+			},
+			config: &internal.Config{
+				Color:    false,
+				Branches: true,
+			},
+			expectedStdout: strings.Join([]string{
+				`|-------------|--------|----------|----------|------------|`,
+				`| File        |  Stmts |  % Stmts | % Branch | Progress   |`,
+				`|-------------|--------|----------|----------|------------|`,
+				`| example     |    6/8 |   75.00% |   60.00% | ■■■■■■■    |`,
+				`|   cmd       |    3/4 |   75.00% |   66.67% | ■■■■■■■    |`,
+				`|     kind.go |    3/4 |   75.00% |   66.67% | ■■■■■■■    |`,
+				`|   main.go   |    3/4 |   75.00% |   50.00% | ■■■■■■■    |`,
+				`|-------------|--------|----------|----------|------------|`,
+				``,
+			}, "\n"),
+			expectedStderr:   "",
+			expectedExitCode: 0,
+		},
+		{
+			title: "with branch coverage of the missing else and default arms",
+			fsys: fstest.MapFS{
+				"go.mod":       {Data: []byte(`module github.com/slavsan/gocov`)},
+				"coverage.out": {Data: []byte(exampleImplicitArmsCoverageOut)},
+				"arms.go":      {Data: []byte(exampleImplicitArmsSourceFile)},
+			},
+			config: &internal.Config{
+				Color:    false,
+				Branches: true,
+			},
+			expectedStdout: strings.Join([]string{
+				`|-----------|--------|----------|----------|------------|`,
+				`| File      |  Stmts |  % Stmts | % Branch | Progress   |`,
+				`|-----------|--------|----------|----------|------------|`,
+				`| example   |  13/16 |   81.25% |   60.00% | ■■■■■■■■   |`,
+				`|   arms.go |  13/16 |   81.25% |   60.00% | ■■■■■■■■   |`,
+				`|-----------|--------|----------|----------|------------|`,
+				``,
+			}, "\n"),
+			expectedStderr:   "",
+			expectedExitCode: 0,
+		},
+		{
+			title: "with exported API coverage",
+			fsys: fstest.MapFS{
+				"go.mod":        {Data: []byte(`module github.com/slavsan/gocov`)},
+				"coverage.out":  {Data: []byte(exampleExportedCoverageOut)},
+				"sdk/client.go": {Data: []byte(exampleExportedSourceFile)},
+			},
+			config: &internal.Config{
+				Color:    false,
+				Exported: true,
+			},
+			expectedStdout: strings.Join([]string{
+				`|-------------|----------|------------|`,
+				`| Package     | Exported | % Exported |`,
+				`|-------------|----------|------------|`,
+				`| example/sdk |      1/4 |     25.00% |`,
+				`|-------------|----------|------------|`,
+				``,
+				`Untested exported symbols:`,
+				`  sdk/client.go:3 sdk.Client`,
+				`  sdk/client.go:9 sdk.(*Client).Get`,
+				`  sdk/client.go:13 sdk.(*Client).Put`,
+				``,
+			}, "\n"),
+			expectedStderr:   "",
+			expectedExitCode: 0,
+		},
+		{
+			title: "with exported API coverage and an ignored file",
+			fsys: fstest.MapFS{
+				"go.mod":        {Data: []byte(`module github.com/slavsan/gocov`)},
+				"coverage.out":  {Data: []byte(exampleExportedCoverageOut + "example/mock/mock.go:3.17,5.2 1 0\n")},
+				"sdk/client.go": {Data: []byte(exampleExportedSourceFile)},
+				"mock/mock.go":  {Data: []byte("package mock\n\nfunc Fake() int {\n\treturn 1\n}\n")},
+				".gocov":        {Data: []byte(`{"ignore": ["example/mock"]}`)},
+			},
+			config: &internal.Config{
+				Color:    false,
+				Exported: true,
+			},
+			expectedStdout: strings.Join([]string{
+				`|-------------|----------|------------|`,
+				`| Package     | Exported | % Exported |`,
+				`|-------------|----------|------------|`,
+				`| example/sdk |      1/4 |     25.00% |`,
+				`|-------------|----------|------------|`,
+				``,
+				`Untested exported symbols:`,
+				`  sdk/client.go:3 sdk.Client`,
+				`  sdk/client.go:9 sdk.(*Client).Get`,
+				`  sdk/client.go:13 sdk.(*Client).Put`,
+				``,
+			}, "\n"),
+			expectedStderr:   "",
+			expectedExitCode: 0,
+		},
+		{
+			title: "with uncovered line ranges wider than the terminal",
+			fsys: fstest.MapFS{
+				"go.mod":       {Data: []byte(`module github.com/slavsan/gospec`)},
+				"coverage.out": {Data: []byte(exampleCoverageOut)},
+			},
+			config: &internal.Config{
+				Color:          false,
+				Flat:           true,
+				UncoveredLines: true,
+				Width:          80,
+			},
+			expectedStdout: strings.Join([]string{
+				`|--------------------|---------|----------|------------|-----------------------|`,
+				`| File               |   Stmts |  % Stmts | Progress   | Uncovered Line #s     |`,
+				`|--------------------|---------|----------|------------|-----------------------|`,
+				`| cmd/cover.go:11    |    0/28 |    0.00% |            | 11-20, 22-46, ...     |`,
+				`| expect.go:135      |   43/86 |   50.00% | ■■■■■      | 135-141, 161-169, ... |`,
+				`| featurespec.go:119 |  92/107 |   85.98% | ■■■■■■■■   | 119-121, 153-155, ... |`,
+				`| gospec.go:1        | 102/102 |  100.00% | ■■■■■■■■■■ |                       |`,
+				`|--------------------|---------|----------|------------|-----------------------|`,
+				``,
+			}, "\n"),
+			expectedStderr:   "",
+			expectedExitCode: 0,
+		},
+		{
+			title: "with invalid table style",
+			fsys: fstest.MapFS{
+				"go.mod":       {Data: []byte(`module github.com/slavsan/gospec`)},
+				"coverage.out": {Data: []byte(exampleCoverageOut)},
+			},
+			config: &internal.Config{
+				Color: false,
+				Style: "double",
+			},
+			expectedStdout:   "",
+			expectedStderr:   "invalid table style: double",
+			expectedExitCode: 1,
+		},
+		{
+			title: "with watermarks defined in .gocov file",
+			fsys: fstest.MapFS{
+				"go.mod":       {Data: []byte(`module github.com/slavsan/gospec`)},
+				"coverage.out": {Data: []byte(exampleCoverageOut)},
+				".gocov": {Data: []byte(strings.Join([]string{
+					`{`,
+					`	"watermarks": {"high": 90, "low": 70}`,
+					`}`,
+				}, "\n"))},
+			},
+			config: &internal.Config{
+				Color: true,
+			},
+			expectedStdout: strings.Join([]string{
+				"|------------------|---------|----------|------------|",
+				"| File             |   Stmts |  % Stmts | Progress   |",
+				"|------------------|---------|----------|------------|",
+				"|\033[0;33m gospec           \033[0m| \033[0;33m237/323\033[0m | \033[0;33m  73.37%\033[0m | \033[0;33m■■■■■■■   \033[0m |",
+				"|\033[0;31m   cmd            \033[0m| \033[0;31m   0/28\033[0m | \033[0;31m   0.00%\033[0m | \033[0;31m          \033[0m |",
+				"|\033[0;31m     cover.go     \033[0m| \033[0;31m   0/28\033[0m | \033[0;31m   0.00%\033[0m | \033[0;31m          \033[0m |",
+				"|\033[0;31m   expect.go      \033[0m| \033[0;31m  43/86\033[0m | \033[0;31m  50.00%\033[0m | \033[0;31m■■■■■     \033[0m |",
+				"|\033[0;33m   featurespec.go \033[0m| \033[0;33m 92/107\033[0m | \033[0;33m  85.98%\033[0m | \033[0;33m■■■■■■■■  \033[0m |",
+				"|\033[0;32m   gospec.go      \033[0m| \033[0;32m102/102\033[0m | \033[0;32m 100.00%\033[0m | \033[0;32m■■■■■■■■■■\033[0m |",
+				"|------------------|---------|----------|------------|",
+				"",
+			}, "\n"),
+			expectedStderr:   "",
+			expectedExitCode: 0,
+		},
+		{
+			title: "with invalid watermarks defined in .gocov file",
+			fsys: fstest.MapFS{
+				"go.mod":       {Data: []byte(`module github.com/slavsan/gospec`)},
+				"coverage.out": {Data: []byte(exampleCoverageOut)},
+				".gocov": {Data: []byte(strings.Join([]string{
+					`{`,
+					`	"watermarks": {"high": 60, "low": 70}`,
+					`}`,
+				}, "\n"))},
+			},
+			config: &internal.Config{
+				Color: false,
+			},
+			expectedStdout:   "",
+			expectedStderr:   "invalid watermarks in .gocov config file: low is greater than high",
+			expectedExitCode: 1,
+		},
+		{
+			title: "with invalid watermarks defined in the global .gocov file",
+			fsys: fstest.MapFS{
+				"go.mod":       {Data: []byte(`module github.com/slavsan/gospec`)},
+				"coverage.out": {Data: []byte(exampleCoverageOut)},
+			},
+			config: &internal.Config{
+				Color: false,
+				Global: &internal.GocovConfig{
+					Watermarks: &internal.Watermarks{High: 60, Low: 70},
+				},
+			},
+			expectedStdout:   "",
+			expectedStderr:   "invalid watermarks in global .gocov config file: low is greater than high",
+			expectedExitCode: 1,
+		},
+		{
+			title: "with only the low watermark given",
+			fsys: fstest.MapFS{
+				"go.mod":       {Data: []byte(`module github.com/slavsan/gospec`)},
+				"coverage.out": {Data: []byte(exampleCoverageOut)},
+			},
+			config: &internal.Config{
+				Color:      false,
+				Watermarks: internal.Watermarks{Low: 70},
+			},
+			expectedStdout:   "",
+			expectedStderr:   "invalid watermarks: low is greater than high",
+			expectedExitCode: 1,
+		},
+		{
+			title: "with only the high watermark given",
+			fsys: fstest.MapFS{
+				"go.mod":       {Data: []byte(`module github.com/slavsan/gospec`)},
+				"coverage.out": {Data: []byte(exampleCoverageOut)},
+			},
+			config: &internal.Config{
+				Color:      true,
+				Watermarks: internal.Watermarks{High: 90},
+			},
+			expectedStdout: strings.Join([]string{
+				"|------------------|---------|----------|------------|",
+				"| File             |   Stmts |  % Stmts | Progress   |",
+				"|------------------|---------|----------|------------|",
+				"|\033[0;33m gospec           \033[0m| \033[0;33m237/323\033[0m | \033[0;33m  73.37%\033[0m | \033[0;33m■■■■■■■   \033[0m |",
+				"|\033[0;33m   cmd            \033[0m| \033[0;33m   0/28\033[0m | \033[0;33m   0.00%\033[0m | \033[0;33m          \033[0m |",
+				"|\033[0;33m     cover.go     \033[0m| \033[0;33m   0/28\033[0m | \033[0;33m   0.00%\033[0m | \033[0;33m          \033[0m |",
+				"|\033[0;33m   expect.go      \033[0m| \033[0;33m  43/86\033[0m | \033[0;33m  50.00%\033[0m | \033[0;33m■■■■■     \033[0m |",
+				"|\033[0;33m   featurespec.go \033[0m| \033[0;33m 92/107\033[0m | \033[0;33m  85.98%\033[0m | \033[0;33m■■■■■■■■  \033[0m |",
+				"|\033[0;32m   gospec.go      \033[0m| \033[0;32m102/102\033[0m | \033[0;32m 100.00%\033[0m | \033[0;32m■■■■■■■■■■\033[0m |",
+				"|------------------|---------|----------|------------|",
+				"",
+			}, "\n"),
+			expectedStderr:   "",
+			expectedExitCode: 0,
+		},
+		{
+			title: "with invalid sort key",
+			fsys: fstest.MapFS{
+				"go.mod":       {Data: []byte(`module github.com/slavsan/gospec`)},
+				"coverage.out": {Data: []byte(exampleCoverageOut)},
+			},
+			config: &internal.Config{
+				Color:  false,
+				SortBy: "size",
+			},
+			expectedStdout:   "",
+			expectedStderr:   "invalid sort key: size",
+			expectedExitCode: 1,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -1,17 +1,31 @@
 package internal
 
 const Script = `const node = JSON.parse(document.querySelector('.tree-data').textContent)
+const config = JSON.parse(document.querySelector('.config-data').textContent)
 const table = document.querySelector('.table')
 const indicator = document.querySelector('.indicator')
 const _ = null
+// the pages of a site come with their breadcrumbs and show their own node
+const site = !!config.breadcrumbs
 
-let currentHash = location.hash.replace('#', '')
+let currentHash = site ? node.path : location.hash.replace('#', '')
 
 renderTable(currentHash)
 
+function nodeExists(node, path) {
+    if (node.path === path) {
+        return true
+    }
+    return !!node.children && node.children.some(c => nodeExists(c, path))
+}
+
 function renderBreadcrumbs(currentHash) {
     const breadcrumbs = document.querySelector('.breadcrumbs')
     breadcrumbs.innerHTML = ''
+    if (site) {
+        renderSiteBreadcrumbs(breadcrumbs)
+        return
+    }
     const allFiles = document.createElement('a')
     allFiles.textContent = 'All Files'
     allFiles.href = '#'
@@ -32,14 +46,37 @@ function renderBreadcrumbs(currentHash) {
             breadcrumbs.appendChild(span)
             return
         }
-        const a = document.createElement('a')
         links.push(p)
+        if (!nodeExists(node, links.join('/'))) {
+            // part of a compacted directory chain
+            const span = document.createElement('span')
+            span.textContent = p
+            breadcrumbs.appendChild(span)
+            return
+        }
+        const a = document.createElement('a')
         a.href = '#' + links.join('/')
         a.textContent = p
         breadcrumbs.appendChild(a)
     })
 }
 
+function renderSiteBreadcrumbs(breadcrumbs) {
+    config.breadcrumbs.forEach((crumb, i) => {
+        if (i !== 0) {
+            const divider = document.createElement('span')
+            divider.textContent = '/'
+            breadcrumbs.appendChild(divider)
+        }
+        const el = document.createElement(crumb.href === undefined ? 'span' : 'a')
+        el.textContent = crumb.name
+        if (crumb.href !== undefined) {
+            el.href = crumb.href
+        }
+        breadcrumbs.appendChild(el)
+    })
+}
+
 function renderSelected(node, currentHash) {
     if (node.path === currentHash) {
         const stats = document.querySelector('.stats')
@@ -62,17 +99,20 @@ function renderSelected(node, currentHash) {
     }
 }
 
+function statusClass(percent) {
+    if (percent >= config.watermarks.high) {
+        return 'ok'
+    } else if (percent >= config.watermarks.low) {
+        return 'warn'
+    }
+    return 'error'
+}
+
 function renderIndicator(percent) {
     indicator.classList.remove('ok')
     indicator.classList.remove('warn')
     indicator.classList.remove('error')
-    if (percent >= 80) {
-        indicator.classList.add('ok')
-    } else if (percent >= 50) {
-        indicator.classList.add('warn')
-    } else {
-        indicator.classList.add('error')
-    }
+    indicator.classList.add(statusClass(percent))
 }
 
 function renderNode(node, currentHash) {
@@ -103,7 +143,7 @@ function renderTable(currentHash) {
 
     renderBreadcrumbs(currentHash)
 
-    if (!currentHash) {
+    if (!currentHash && !site) {
         const stats = document.querySelector('.stats')
         stats.textContent = node.covered + '/' + node.all + ' (' + node.percent + '%)'
         renderIndicator(node.percent)
@@ -114,6 +154,9 @@ function renderTable(currentHash) {
 }
 
 window.addEventListener('hashchange', function (e) {
+    if (site) {
+        return
+    }
     const parts = e.newURL.split('#')
     let currentHash = ''
     if (parts.length === 2) {
@@ -124,13 +167,7 @@ window.addEventListener('hashchange', function (e) {
 
 function renderRow(node, currentHash) {
     e('tr', _, { onInit: (tr) => {
-        if (node.percent >= 80) {
-            tr.classList.add('ok')
-        } else if (node.percent >= 50) {
-            tr.classList.add('warn')
-        } else {
-            tr.classList.add('error')
-        }
+        tr.classList.add(statusClass(node.percent))
         table.appendChild(tr)
     }}, [
         e('td', _, { onInit: (td) => {
@@ -140,7 +177,7 @@ function renderRow(node, currentHash) {
         }}, [
             e('a', _, { onInit: (a) => {
                 a.textContent = node.name
-                a.href = '#' + node.path
+                a.href = node.href === undefined ? '#' + node.path : node.href
             }})
         ]),
         e('td', _, { onInit: (td) => {
@@ -149,6 +186,14 @@ function renderRow(node, currentHash) {
         e('td', _, { onInit: (td) => {
             td.textContent = node.percent + '%'
         }}),
+        e('td', _, { onInit: (td) => {
+            td.textContent = node.linesPercent + '%'
+            td.title = '% Lines'
+        }}),
+        ...(node.branches === undefined ? [] : [e('td', _, { onInit: (td) => {
+            td.textContent = node.branchPercent + '%'
+            td.title = '% Branch'
+        }})]),
         e('td', _, _, [
             e('div', _, { onInit: (div) => {
                 div.classList.add('progress')
@@ -0,0 +1,116 @@
+package internal
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+)
+
+const siteRootName = "All Files"
+
+// sitePage returns the path of the page of the node, relative to the root
+// of the site: index.html in the directory of a directory node, or the
+// name of the file with .html appended for a file node.
+func sitePage(n *Node, root bool) string {
+	switch {
+	case root:
+		return "index.html"
+	case n.value != nil:
+		return n.fullPath + ".html"
+	default:
+		return n.fullPath + "/index.html"
+	}
+}
+
+// siteHref returns the link to a page from another page, both relative to
+// the root of the site. The names are escaped, so that a # or a ? in them is
+// not taken for the fragment or the query of the link.
+func siteHref(from, to string) string {
+	fromDirs := strings.Split(from, "/")
+	fromDirs = fromDirs[:len(fromDirs)-1]
+	toParts := strings.Split(to, "/")
+	common := 0
+	for common < len(fromDirs) && common < len(toParts)-1 && fromDirs[common] == toParts[common] {
+		common++
+	}
+	segments := make([]string, 0, len(toParts)-common)
+	for _, part := range toParts[common:] {
+		segments = append(segments, url.PathEscape(part))
+	}
+	return strings.Repeat("../", len(fromDirs)-common) + strings.Join(segments, "/")
+}
+
+// ReportHTMLDir writes the report as a site, with a page for each directory
+// and each file. Unlike the single page report, every page only holds the
+// data of its own node and the source of its own file.
+func (cmd *Cmd) ReportHTMLDir(tree *Tree) {
+	report, err := cmd.newHTMLReport(tree)
+	if err != nil {
+		_, _ = fmt.Fprint(cmd.stderr, err.Error())
+		cmd.exiter.Exit(1)
+		return
+	}
+	if err = cmd.writeSitePages(report, tree.Root, nil); err != nil {
+		_, _ = fmt.Fprint(cmd.stderr, err.Error())
+		cmd.exiter.Exit(1)
+	}
+}
+
+// writeSitePages writes the page of the node and the pages below it, the
+// ancestors are the nodes from the root down to the parent of the node.
+func (cmd *Cmd) writeSitePages(report *htmlReport, n *Node, ancestors []*Node) error {
+	root := len(ancestors) == 0
+	page := sitePage(n, root)
+	children := n.sortedChildren(cmd.config)
+
+	name := n.path
+	if root {
+		name = siteRootName
+	}
+	node := n.htmlNode(cmd.config, name)
+	var sources []*htmlSource
+	if n.value != nil {
+		source, err := cmd.htmlSource(n)
+		if err != nil {
+			return fmt.Errorf("failed to inspect file: %w", err)
+		}
+		sources = append(sources, source)
+	} else {
+		node.Children = make([]*htmlNode, 0, len(children))
+		for _, c := range children {
+			child := c.htmlNode(cmd.config, c.path)
+			child.Href = siteHref(page, sitePage(c, false))
+			node.Children = append(node.Children, child)
+		}
+	}
+
+	crumbs := make([]*htmlCrumb, 0, len(ancestors)+1)
+	for i, a := range ancestors {
+		crumbName := a.path
+		if i == 0 {
+			crumbName = siteRootName
+		}
+		crumbs = append(crumbs, &htmlCrumb{Name: crumbName, Href: siteHref(page, sitePage(a, i == 0))})
+	}
+	crumbs = append(crumbs, &htmlCrumb{Name: name})
+
+	if err := cmd.fw.Open(path.Join(cmd.config.HTMLDir, page)); err != nil {
+		return fmt.Errorf("failed to write %s: %w", page, err)
+	}
+	err := report.write(cmd.fw, node, sources, crumbs)
+	if closeErr := cmd.fw.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %w", page, err)
+	}
+
+	ancestors = append(ancestors, n)
+	for _, c := range children {
+		if err := cmd.writeSitePages(report, c, ancestors); err != nil {
+			return err
+		}
+	}
+	return nil
+}
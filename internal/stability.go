@@ -0,0 +1,240 @@
+package internal
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const defaultStabilityRuns = 3
+
+// stableBlock is a block of the profiles with the number of runs which hit
+// it.
+type stableBlock struct {
+	name   string
+	report *covReport
+	hits   int
+}
+
+// Stability runs the tests several times, or takes the given profiles, and
+// reports the blocks which are hit in some of the runs only.
+func (cmd *Cmd) Stability(profiles []string) {
+	if !isValidStyle(cmd.config.Style) {
+		_, _ = fmt.Fprintf(cmd.stderr, "invalid table style: %s", cmd.config.Style)
+		cmd.exiter.Exit(1)
+		return
+	}
+	if cmd.config.Runs != 0 && cmd.config.Runs < 2 {
+		_, _ = fmt.Fprintf(cmd.stderr, "invalid number of runs: %d, --runs must be at least 2", cmd.config.Runs)
+		cmd.exiter.Exit(1)
+		return
+	}
+	unstable, err := cmd.stability(profiles)
+	if err != nil {
+		_, _ = fmt.Fprint(cmd.stderr, err.Error())
+		cmd.exiter.Exit(1)
+		return
+	}
+	if unstable > 0 {
+		_, _ = fmt.Fprintf(cmd.stderr, "coverage is unstable: %d blocks are hit in some of the runs only\n", unstable)
+		cmd.exiter.Exit(1)
+	}
+}
+
+func (cmd *Cmd) stability(profiles []string) (int, error) {
+	module, err := getModule(cmd.fsys)
+	if err != nil {
+		return 0, err
+	}
+	moduleDir := filepath.Dir(module)
+
+	if len(profiles) == 0 {
+		if profiles, err = cmd.stabilityRuns(); err != nil {
+			return 0, err
+		}
+	}
+	if len(profiles) < 2 {
+		return 0, fmt.Errorf("at least 2 runs are needed to compare their coverage") //nolint:goerr113
+	}
+
+	blocks := map[string]*stableBlock{}
+	for _, profile := range profiles {
+		if err = cmd.readRunHits(profile, blocks); err != nil {
+			return 0, err
+		}
+	}
+
+	for key, block := range blocks {
+		if isIgnoredPath(strings.TrimPrefix(block.name, moduleDir+"/"), cmd.config.File) {
+			delete(blocks, key)
+		}
+	}
+
+	var unstable []*stableBlock
+	for _, block := range blocks {
+		if block.hits > 0 && block.hits < len(profiles) {
+			unstable = append(unstable, block)
+		}
+	}
+	if len(unstable) == 0 {
+		_, _ = fmt.Fprintf(cmd.stdout, "coverage is stable across %d runs\n", len(profiles))
+		return 0, nil
+	}
+	sort.Slice(unstable, func(i, j int) bool {
+		if unstable[i].name != unstable[j].name {
+			return unstable[i].name < unstable[j].name
+		}
+		if unstable[i].report.StartLine != unstable[j].report.StartLine {
+			return unstable[i].report.StartLine < unstable[j].report.StartLine
+		}
+		return unstable[i].report.StartColumn < unstable[j].report.StartColumn
+	})
+
+	cmd.stabilityTable(blocks, unstable, moduleDir)
+
+	_, _ = fmt.Fprint(cmd.stdout, "\nUnstable blocks:\n")
+	for _, block := range unstable {
+		_, _ = fmt.Fprintf(cmd.stdout, "  %s hit in %d of %d runs\n",
+			blockKey(strings.TrimPrefix(block.name, moduleDir+"/"), block.report), block.hits, len(profiles))
+	}
+	return len(unstable), nil
+}
+
+// stabilityRuns runs the tests the configured number of times, each run
+// into a profile of its own. The test cache is disabled so that every run
+// really executes the tests, and a failing run does not stop the others. The
+// profile of a previous invocation is removed before each run, so that a run
+// which writes none is left out instead of comparing the stale one.
+// A build failure writes a profile without any blocks, which is left out too.
+func (cmd *Cmd) stabilityRuns() ([]string, error) {
+	runs := cmd.config.Runs
+	if runs == 0 {
+		runs = defaultStabilityRuns
+	}
+	dir := path.Join(cmd.config.cacheDir(), "stability")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create the cache directory: %w", err)
+	}
+	profiles := make([]string, 0, runs)
+	for i := 0; i < runs; i++ {
+		profile := path.Join(dir, strconv.Itoa(i)+".out")
+		if err := os.Remove(profile); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("failed to remove %s: %w", profile, err)
+		}
+		if err := cmd.runTests(profile, "-count=1"); err != nil {
+			_, _ = fmt.Fprintf(cmd.stderr, "failed to run `go test` command: %s\n", err.Error())
+		}
+		if !hasBlocks(profile) {
+			_, _ = fmt.Fprintf(cmd.stderr, "run %d wrote no coverage profile, leaving it out\n", i+1)
+			continue
+		}
+		profiles = append(profiles, profile)
+	}
+	return profiles, nil
+}
+
+// hasBlocks tells whether the profile exists and has any blocks after the
+// `mode` line.
+func hasBlocks(profile string) bool {
+	data, err := os.ReadFile(profile)
+	if err != nil {
+		return false
+	}
+	_, blocks, _ := strings.Cut(string(data), "\n")
+	return strings.TrimSpace(blocks) != ""
+}
+
+// readRunHits counts a run for every block the profile hits. A block can be
+// in the profile more than once, once per test binary, and counts once.
+func (cmd *Cmd) readRunHits(profile string, blocks map[string]*stableBlock) error {
+	data, err := cmd.readFile(profile)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", profile, err)
+	}
+
+	hit := map[string]bool{}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Scan() // skip the `mode` line
+	if line := scanner.Text(); !strings.HasPrefix(line, "mode: ") {
+		return fmt.Errorf("invalid coverage profile %s", profile) //nolint:goerr113
+	}
+	for scanner.Scan() {
+		line := scanner.Text()
+		colonIndex := strings.Index(line, ":")
+		if colonIndex == -1 {
+			continue
+		}
+		report, err := parseLine(line[colonIndex+1:])
+		if err != nil {
+			return fmt.Errorf("failed to parse %s: %w", profile, err)
+		}
+		key := blockKey(line[:colonIndex], report)
+		if _, ok := blocks[key]; !ok {
+			blocks[key] = &stableBlock{name: line[:colonIndex], report: report}
+		}
+		if report.Hits > 0 {
+			hit[key] = true
+		}
+	}
+	for key := range hit {
+		blocks[key].hits++
+	}
+	return nil
+}
+
+// stabilityTable prints the number of unstable blocks of each file, the
+// least stable files first.
+func (cmd *Cmd) stabilityTable(blocks map[string]*stableBlock, unstable []*stableBlock, moduleDir string) {
+	var (
+		all    = map[string]int{}
+		counts = map[string]int{}
+		names  []string
+	)
+	for _, block := range blocks {
+		all[block.name]++
+	}
+	for _, block := range unstable {
+		if counts[block.name] == 0 {
+			names = append(names, block.name)
+		}
+		counts[block.name]++
+	}
+	sort.SliceStable(names, func(i, j int) bool {
+		return counts[names[i]] > counts[names[j]]
+	})
+
+	columns := []*column{
+		{title: "File", width: len("File"), minWidth: len("File")},
+		{title: "Blocks", width: len("Blocks"), right: true},
+		{title: "Unstable", width: len("Unstable"), right: true},
+	}
+	rows := make([][]string, 0, len(names))
+	for _, name := range names {
+		row := []string{
+			strings.TrimPrefix(name, moduleDir+"/"),
+			strconv.Itoa(all[name]),
+			strconv.Itoa(counts[name]),
+		}
+		for i, cell := range row {
+			if len(cell) > columns[i].width {
+				columns[i].width = len(cell)
+			}
+		}
+		rows = append(rows, row)
+	}
+
+	tbl := newTable(cmd.stdout, cmd.config.Style, columns)
+	tbl.fit(cmd.config.Width)
+	tbl.header()
+	for _, row := range rows {
+		tbl.row(row, cmd.config.paint(Red), cmd.config.paint(NoColor))
+	}
+	tbl.footer()
+}
@@ -0,0 +1,267 @@
+//nolint:funlen
+package internal_test
+
+import (
+	"bytes"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/slavsan/gocov/internal"
+)
+
+const exampleStabilityRun1 = `mode: set
+example/main.go:3.13,4.26 1 1
+example/main.go:4.26,6.3 1 1
+example/main.go:9.18,10.11 1 1
+example/main.go:10.11,12.3 1 0
+example/cmd/kind.go:3.25,4.9 1 1
+example/cmd/kind.go:5.13,6.20 1 0
+example/cmd/kind.go:7.14,8.16 1 1
+example/cmd/kind.go:9.10,10.20 1 1
+`
+
+const exampleStabilityRun2 = `mode: set
+example/main.go:3.13,4.26 1 1
+example/main.go:4.26,6.3 1 1
+example/main.go:9.18,10.11 1 1
+example/main.go:10.11,12.3 1 0
+example/cmd/kind.go:3.25,4.9 1 1
+example/cmd/kind.go:5.13,6.20 1 1
+example/cmd/kind.go:7.14,8.16 1 0
+example/cmd/kind.go:9.10,10.20 1 1
+example/cmd/kind.go:3.25,4.9 1 0
+example/cmd/kind.go:7.14,8.16 1 0
+`
+
+func TestStability(t *testing.T) {
+	testCases := []struct {
+		title            string
+		fsys             fs.StatFS
+		config           *internal.Config
+		args             []string
+		expectedStdout   string
+		expectedStderr   string
+		expectedExitCode int
+	}{
+		{
+			title: "with blocks hit in some of the runs only",
+			fsys: fstest.MapFS{
+				"go.mod": {Data: []byte(`module github.com/slavsan/gocov`)},
+				"1.out":  {Data: []byte(exampleStabilityRun1)},
+				"2.out":  {Data: []byte(exampleStabilityRun2)},
+				"3.out":  {Data: []byte(exampleStabilityRun1)},
+			},
+			config: &internal.Config{
+				Color: false,
+			},
+			args: []string{"1.out", "2.out", "3.out"},
+			expectedStdout: strings.Join([]string{
+				`|---------------------|--------|----------|`,
+				`| File                | Blocks | Unstable |`,
+				`|---------------------|--------|----------|`,
+				`| example/cmd/kind.go |      4 |        2 |`,
+				`|---------------------|--------|----------|`,
+				``,
+				`Unstable blocks:`,
+				`  example/cmd/kind.go:5.13,6.20 hit in 1 of 3 runs`,
+				`  example/cmd/kind.go:7.14,8.16 hit in 2 of 3 runs`,
+				``,
+			}, "\n"),
+			expectedStderr:   "coverage is unstable: 2 blocks are hit in some of the runs only\n",
+			expectedExitCode: 1,
+		},
+		{
+			title: "with the same blocks hit in every run",
+			fsys: fstest.MapFS{
+				"go.mod": {Data: []byte(`module github.com/slavsan/gocov`)},
+				"1.out":  {Data: []byte(exampleStabilityRun1)},
+				"2.out":  {Data: []byte(exampleStabilityRun1)},
+			},
+			config: &internal.Config{
+				Color: false,
+			},
+			args:             []string{"1.out", "2.out"},
+			expectedStdout:   "coverage is stable across 2 runs\n",
+			expectedStderr:   "",
+			expectedExitCode: 0,
+		},
+		{
+			title: "with the unstable blocks in an ignored file",
+			fsys: fstest.MapFS{
+				"go.mod": {Data: []byte(`module github.com/slavsan/gocov`)},
+				".gocov": {Data: []byte(`{"ignore": ["example/cmd"]}`)},
+				"1.out":  {Data: []byte(exampleStabilityRun1)},
+				"2.out":  {Data: []byte(exampleStabilityRun2)},
+			},
+			config: &internal.Config{
+				Color: false,
+			},
+			args:             []string{"1.out", "2.out"},
+			expectedStdout:   "coverage is stable across 2 runs\n",
+			expectedStderr:   "",
+			expectedExitCode: 0,
+		},
+		{
+			title: "with a single profile",
+			fsys: fstest.MapFS{
+				"go.mod": {Data: []byte(`module github.com/slavsan/gocov`)},
+				"1.out":  {Data: []byte(exampleStabilityRun1)},
+			},
+			config: &internal.Config{
+				Color: false,
+			},
+			args:             []string{"1.out"},
+			expectedStdout:   "",
+			expectedStderr:   "at least 2 runs are needed to compare their coverage",
+			expectedExitCode: 1,
+		},
+		{
+			title: "with a negative number of runs",
+			fsys: fstest.MapFS{
+				"go.mod": {Data: []byte(`module github.com/slavsan/gocov`)},
+			},
+			config: &internal.Config{
+				Color: false,
+				Runs:  -1,
+			},
+			expectedStdout:   "",
+			expectedStderr:   "invalid number of runs: -1, --runs must be at least 2",
+			expectedExitCode: 1,
+		},
+		{
+			title: "with a single run",
+			fsys: fstest.MapFS{
+				"go.mod": {Data: []byte(`module github.com/slavsan/gocov`)},
+			},
+			config: &internal.Config{
+				Color: false,
+				Runs:  1,
+			},
+			expectedStdout:   "",
+			expectedStderr:   "invalid number of runs: 1, --runs must be at least 2",
+			expectedExitCode: 1,
+		},
+		{
+			title: "with a missing profile",
+			fsys: fstest.MapFS{
+				"go.mod": {Data: []byte(`module github.com/slavsan/gocov`)},
+				"1.out":  {Data: []byte(exampleStabilityRun1)},
+			},
+			config: &internal.Config{
+				Color: false,
+			},
+			args:             []string{"1.out", "2.out"},
+			expectedStdout:   "",
+			expectedStderr:   "failed to open 2.out: open 2.out: file does not exist",
+			expectedExitCode: 1,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.title, func(t *testing.T) {
+			var stdout bytes.Buffer
+			var stderr bytes.Buffer
+			exiter := &exiterMock{}
+			internal.NewCommand(&stdout, &stderr, tc.fsys, tc.config, exiter, &fileWriterMock{}).Exec(internal.Stability, tc.args)
+			if tc.expectedStdout != stdout.String() {
+				t.Errorf("table does not match\n\texpected:\n`%s`\n\tactual:\n`%s`\n", tc.expectedStdout, stdout.String())
+			}
+			if tc.expectedStderr != stderr.String() {
+				t.Errorf("table does not match\n\texpected:\n`%s`\n\tactual:\n`%s`\n", tc.expectedStderr, stderr.String())
+			}
+			if tc.expectedExitCode != exiter.code {
+				t.Errorf("exit code does not match\n\texpected:\n`%d`\n\tactual:\n`%d`\n", tc.expectedExitCode, exiter.code)
+			}
+		})
+	}
+}
+
+func TestStabilityWithAbsoluteProfiles(t *testing.T) {
+	dir := t.TempDir()
+	var profiles []string
+	for i, run := range []string{exampleStabilityRun1, exampleStabilityRun1} {
+		profile := filepath.Join(dir, strconv.Itoa(i)+".out")
+		if err := os.WriteFile(profile, []byte(run), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		profiles = append(profiles, profile)
+	}
+	fsys := fstest.MapFS{
+		"go.mod": {Data: []byte(`module github.com/slavsan/gocov`)},
+	}
+
+	var (
+		stdout bytes.Buffer
+		stderr bytes.Buffer
+		exiter = &exiterMock{}
+	)
+	internal.NewCommand(&stdout, &stderr, fsys, &internal.Config{}, exiter, &fileWriterMock{}).Exec(internal.Stability, profiles)
+	if stderr.String() != "" || exiter.code != 0 {
+		t.Fatalf("unexpected failure: %s (exit code %d)", stderr.String(), exiter.code)
+	}
+	if expected := "coverage is stable across 2 runs\n"; stdout.String() != expected {
+		t.Errorf("output does not match\n\texpected:\n`%s`\n\tactual:\n`%s`\n", expected, stdout.String())
+	}
+}
+
+func TestStabilityWithoutProfiles(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("the go command is needed to run the tests")
+	}
+	moduleDir := t.TempDir()
+	files := map[string]string{
+		"go.mod":      "module example\n\ngo 1.19\n",
+		"max.go":      "package example\n\nfunc Max() int { return undefined }\n",
+		"max_test.go": "package example\n",
+	}
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(moduleDir, name), []byte(data), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// the profiles of a previous invocation are not compared
+	cacheDir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(cacheDir, "stability"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for i, run := range []string{exampleStabilityRun1, exampleStabilityRun1} {
+		profile := filepath.Join(cacheDir, "stability", strconv.Itoa(i)+".out")
+		if err := os.WriteFile(profile, []byte(run), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = os.Chdir(moduleDir); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.Chdir(wd) }()
+
+	var (
+		stdout bytes.Buffer
+		stderr bytes.Buffer
+		exiter = &exiterMock{}
+	)
+	config := &internal.Config{Runs: 2, CacheDir: cacheDir}
+	internal.NewCommand(&stdout, &stderr, os.DirFS(moduleDir).(fs.StatFS), config, exiter, &fileWriterMock{}).Exec(internal.Stability, nil) //nolint:forcetypeassert
+	for _, expected := range []string{
+		"run 1 wrote no coverage profile, leaving it out\n",
+		"run 2 wrote no coverage profile, leaving it out\n",
+	} {
+		if !strings.Contains(stderr.String(), expected) {
+			t.Errorf("stderr does not contain `%s`\n\tactual:\n`%s`\n", expected, stderr.String())
+		}
+	}
+	if !strings.HasSuffix(stderr.String(), "at least 2 runs are needed to compare their coverage") || exiter.code != 1 {
+		t.Errorf("expected the runs without profiles to be left out, got exit code %d and\n`%s`\n", exiter.code, stderr.String())
+	}
+}
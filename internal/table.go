@@ -0,0 +1,223 @@
+package internal
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	StyleASCII   = "ascii"
+	StyleUnicode = "unicode"
+	StyleRounded = "rounded"
+	StyleNone    = "none"
+)
+
+// boxStyle holds the characters used for drawing the table borders. The
+// top, middle and bottom rules are made of a left edge, a column junction
+// and a right edge.
+type boxStyle struct {
+	horizontal string
+	vertical   string
+	top        [3]string
+	middle     [3]string
+	bottom     [3]string
+	ellipsis   string
+}
+
+var boxStyles = map[string]boxStyle{
+	StyleASCII: {
+		horizontal: "-",
+		vertical:   "|",
+		top:        [3]string{"|", "|", "|"},
+		middle:     [3]string{"|", "|", "|"},
+		bottom:     [3]string{"|", "|", "|"},
+		ellipsis:   "...",
+	},
+	StyleUnicode: {
+		horizontal: "─",
+		vertical:   "│",
+		top:        [3]string{"┌", "┬", "┐"},
+		middle:     [3]string{"├", "┼", "┤"},
+		bottom:     [3]string{"└", "┴", "┘"},
+		ellipsis:   "…",
+	},
+	StyleRounded: {
+		horizontal: "─",
+		vertical:   "│",
+		top:        [3]string{"╭", "┬", "╮"},
+		middle:     [3]string{"├", "┼", "┤"},
+		bottom:     [3]string{"╰", "┴", "╯"},
+		ellipsis:   "…",
+	},
+	StyleNone: {
+		ellipsis: "...",
+	},
+}
+
+func isValidStyle(style string) bool {
+	if style == "" {
+		return true
+	}
+	_, ok := boxStyles[style]
+	return ok
+}
+
+type column struct {
+	title string
+	width int
+	right bool
+	// minWidth is the width the column can be shrunk to when the table does
+	// not fit in the terminal, zero means the column can not be shrunk.
+	minWidth int
+	// truncate cuts the values which do not fit at their end instead of
+	// eliding their middle.
+	truncate bool
+}
+
+type table struct {
+	w       io.Writer
+	style   boxStyle
+	columns []*column
+}
+
+func newTable(w io.Writer, style string, columns []*column) *table {
+	if style == "" {
+		style = StyleASCII
+	}
+	return &table{w: w, style: boxStyles[style], columns: columns}
+}
+
+func (t *table) width() int {
+	s := t.style
+	width := utf8.RuneCountInString(s.vertical) * (len(t.columns) + 1)
+	for _, c := range t.columns {
+		width += c.width + 2
+	}
+	return width
+}
+
+// fit shrinks the columns, starting from the last one, until the table fits
+// in maxWidth. A maxWidth of zero means there is no limit.
+func (t *table) fit(maxWidth int) {
+	if maxWidth <= 0 {
+		return
+	}
+	excess := t.width() - maxWidth
+	for i := len(t.columns) - 1; i >= 0 && excess > 0; i-- {
+		c := t.columns[i]
+		if c.minWidth == 0 || c.width <= c.minWidth {
+			continue
+		}
+		shrink := c.width - c.minWidth
+		if shrink > excess {
+			shrink = excess
+		}
+		c.width -= shrink
+		excess -= shrink
+	}
+}
+
+func (t *table) rule(edges [3]string) {
+	if t.style.horizontal == "" {
+		return
+	}
+	parts := make([]string, 0, len(t.columns))
+	for _, c := range t.columns {
+		parts = append(parts, strings.Repeat(t.style.horizontal, c.width+2))
+	}
+	_, _ = fmt.Fprintf(t.w, "%s%s%s\n", edges[0], strings.Join(parts, edges[1]), edges[2])
+}
+
+func (t *table) header() {
+	t.rule(t.style.top)
+	titles := make([]string, 0, len(t.columns))
+	for _, c := range t.columns {
+		titles = append(titles, c.title)
+	}
+	t.row(titles, "", "")
+	t.rule(t.style.middle)
+}
+
+func (t *table) footer() {
+	t.rule(t.style.bottom)
+}
+
+// row writes the cells of a single row. The color of the first cell spans
+// its padding as well, so that the label stands out as a whole.
+func (t *table) row(cells []string, color, noColor string) {
+	var sb strings.Builder
+	for i, c := range t.columns {
+		var cell string
+		if c.truncate {
+			cell = pad(c, t.truncate(cells[i], c.width))
+		} else {
+			cell = pad(c, t.elide(cells[i], c.width))
+		}
+		sb.WriteString(t.style.vertical)
+		if i == 0 {
+			_, _ = fmt.Fprintf(&sb, "%s %s %s", color, cell, noColor)
+			continue
+		}
+		_, _ = fmt.Fprintf(&sb, " %s%s%s ", color, cell, noColor)
+	}
+	sb.WriteString(t.style.vertical)
+	_, _ = fmt.Fprintf(t.w, "%s\n", sb.String())
+}
+
+// elide shortens the value to the given width by replacing its middle
+// with an ellipsis, keeping any leading indentation intact.
+func (t *table) elide(value string, width int) string {
+	if utf8.RuneCountInString(value) <= width {
+		return value
+	}
+	trimmed := strings.TrimLeft(value, " ")
+	indent := value[:len(value)-len(trimmed)]
+	if width-len(indent) > utf8.RuneCountInString(t.style.ellipsis)+1 {
+		return indent + elideMiddle(trimmed, width-len(indent), t.style.ellipsis)
+	}
+	return elideMiddle(value, width, t.style.ellipsis)
+}
+
+// truncate shortens a comma separated list to the given width, dropping the
+// items which do not fit and marking the cut with an ellipsis.
+func (t *table) truncate(value string, width int) string {
+	if utf8.RuneCountInString(value) <= width {
+		return value
+	}
+	keep := width - utf8.RuneCountInString(t.style.ellipsis)
+	if keep <= 0 {
+		return string([]rune(value)[:width])
+	}
+	cut := string([]rune(value)[:keep])
+	if index := strings.LastIndex(cut, ", "); index != -1 {
+		cut = cut[:index+2]
+	}
+	return cut + t.style.ellipsis
+}
+
+func elideMiddle(value string, width int, ellipsis string) string {
+	runes := []rune(value)
+	if len(runes) <= width {
+		return value
+	}
+	keep := width - utf8.RuneCountInString(ellipsis)
+	if keep <= 0 {
+		return string(runes[:width])
+	}
+	head := keep / 2
+	tail := keep - head
+	return string(runes[:head]) + ellipsis + string(runes[len(runes)-tail:])
+}
+
+func pad(c *column, value string) string {
+	padding := c.width - utf8.RuneCountInString(value)
+	if padding <= 0 {
+		return value
+	}
+	if c.right {
+		return strings.Repeat(" ", padding) + value
+	}
+	return value + strings.Repeat(" ", padding)
+}
@@ -1,43 +1,222 @@
 package internal
 
-const tmpl = `<!doctype html>
-<head>
-<title>My coverage</title>
-<style>
+import (
+	"html/template"
+	"strconv"
+)
+
+// htmlNode is the data of a node of the tree in the html report.
+type htmlNode struct {
+	Name         string      `json:"name"`
+	All          int         `json:"all"`
+	Covered      int         `json:"covered"`
+	Percent      htmlPercent `json:"percent"`
+	Lines        int         `json:"lines"`
+	LinesCovered int         `json:"linesCovered"`
+	LinesPercent htmlPercent `json:"linesPercent"`
+	Path         string      `json:"path"`
+	Level        int         `json:"level"`
+	*htmlBranches
+	// Href links to the page of the node in a site.
+	Href           string      `json:"href,omitempty"`
+	Type           string      `json:"type"`
+	UncoveredLines *string     `json:"uncoveredLines,omitempty"`
+	Children       []*htmlNode `json:"children,omitempty"`
+}
+
+// htmlBranches is the branch coverage of a node, only reported when asked
+// for.
+type htmlBranches struct {
+	Branches      int         `json:"branches"`
+	BranchesTaken int         `json:"branchesTaken"`
+	BranchPercent htmlPercent `json:"branchPercent"`
+}
+
+// htmlPercent is a percentage, always encoded with two decimals.
+type htmlPercent float64
+
+func (p htmlPercent) MarshalJSON() ([]byte, error) {
+	return strconv.AppendFloat(nil, float64(p), 'f', 2, 64), nil
+}
+
+func (p htmlPercent) String() string {
+	return strconv.FormatFloat(float64(p), 'f', 2, 64)
+}
+
+// htmlSource is the rendered source of a file, already escaped.
+type htmlSource struct {
+	Path string
+	Code template.HTML
+}
+
+// htmlConfig is the config the script of the report reads.
+type htmlConfig struct {
+	Watermarks  htmlWatermarks `json:"watermarks"`
+	Breadcrumbs []*htmlCrumb   `json:"breadcrumbs,omitempty"`
+}
+
+type htmlWatermarks struct {
+	High htmlPercent `json:"high"`
+	Low  htmlPercent `json:"low"`
+}
+
+// htmlCrumb is a link to an ancestor page of a site, the current page comes
+// last and without a link.
+type htmlCrumb struct {
+	Name string `json:"name"`
+	Href string `json:"href,omitempty"`
+}
+
+// htmlStats is the coverage of the whole report.
+type htmlStats struct {
+	Statements    int
+	Covered       int
+	Percent       htmlPercent
+	Lines         int
+	LinesCovered  int
+	LinesPercent  htmlPercent
+	Branches      int
+	BranchesTaken int
+	BranchPercent htmlPercent
+}
+
+// htmlData is what the page template is executed with, and what custom
+// templates can use:
+//
+//	.Title   the title of the report
+//	.CSS     the css of the theme, followed by the custom css
+//	.Tree    the node of the page, with the directories and files below it
+//	.Stats   the coverage of the whole report
+//	.Sources the rendered sources of the files of the page
+//	.Config  the watermarks and, for the pages of a site, the breadcrumbs
+//	.Script  the script rendering the table from .Tree and .Config
+type htmlData struct {
+	Title   string
+	CSS     template.CSS
+	Tree    *htmlNode
+	Stats   *htmlStats
+	Sources []*htmlSource
+	Config  *htmlConfig
+	Script  template.JS
+}
+
+const (
+	ThemeLight        = "light"
+	ThemeDark         = "dark"
+	ThemeHighContrast = "high-contrast"
+)
+
+const defaultHTMLTitle = "Coverage report"
+
+// themes hold the colors the css of the page is written with.
+var themes = map[string]string{
+	ThemeLight: `:root {
+        --background: white;
+        --text: black;
+        --link: rgb(0,0,238);
+        --border: #aaa;
+        --ok: rgb(233,245,212);
+        --ok-strong: rgb(94,145,53);
+        --warn: rgb(253,245,200);
+        --warn-strong: rgb(242,202,83);
+        --error: pink;
+        --error-strong: darkred;
+        --uncovered: pink;
+        --heat-1: rgb(222,235,247);
+        --heat-2: rgb(158,202,225);
+        --heat-3: rgb(253,174,107);
+        --heat-4: rgb(230,85,13);
+        --heat-text: white;
+    }`,
+	ThemeDark: `:root {
+        --background: rgb(30,30,30);
+        --text: rgb(212,212,212);
+        --link: rgb(108,182,255);
+        --border: #555;
+        --ok: rgb(32,56,32);
+        --ok-strong: rgb(94,145,53);
+        --warn: rgb(70,60,20);
+        --warn-strong: rgb(242,202,83);
+        --error: rgb(80,30,30);
+        --error-strong: rgb(220,70,70);
+        --uncovered: rgb(110,40,40);
+        --heat-1: rgb(30,50,80);
+        --heat-2: rgb(40,90,140);
+        --heat-3: rgb(170,100,40);
+        --heat-4: rgb(230,85,13);
+        --heat-text: white;
+    }`,
+	ThemeHighContrast: `:root {
+        --background: black;
+        --text: white;
+        --link: yellow;
+        --border: white;
+        --ok: rgb(0,80,0);
+        --ok-strong: lime;
+        --warn: rgb(90,70,0);
+        --warn-strong: yellow;
+        --error: rgb(110,0,0);
+        --error-strong: red;
+        --uncovered: rgb(160,0,0);
+        --heat-1: navy;
+        --heat-2: blue;
+        --heat-3: darkorange;
+        --heat-4: red;
+        --heat-text: white;
+    }`,
+}
+
+const themeCSS = `
+    body { background: var(--background); color: var(--text); }
+    a { color: var(--link); text-decoration: none; }
     div { border: 1px solid transparent; }
-    .table { border: 1px solid #aaa; border-collapse: collapse; }
+    .table { border: 1px solid var(--border); border-collapse: collapse; }
     .source { display: none; }
     .visible { display: block; }
-    td { border: 1px solid #aaa; padding: 5px; }
-    a { text-decoration: none; }
-    .ok { background: rgb(233,245,212); }
-    .ok2 { background: rgb(94,145,53); }
-    .warn { background: rgb(253,245,200); }
-    .warn2 { background: rgb(242,202,83); }
-    .error { background: pink; }
+    td { border: 1px solid var(--border); padding: 5px; }
+    .ok { background: var(--ok); }
+    .ok2 { background: var(--ok-strong); }
+    .warn { background: var(--warn); }
+    .warn2 { background: var(--warn-strong); }
+    .error { background: var(--error); }
     .progress { width: 100px; height: 20px; }
     .progress > div { height: calc(20px - 1px); }
-    .ok .progress { border: 1px solid rgb(94,145,53); }
-    .ok .progress > div { background: rgb(94,145,53); }
-    .warn .progress { border: 1px solid rgb(242,202,83); }
-    .warn .progress > div { background: rgb(242,202,83); }
-    .error .progress { border: 1px solid darkred; }
-    .error .progress > div { background: darkred; }
+    .ok .progress { border: 1px solid var(--ok-strong); }
+    .ok .progress > div { background: var(--ok-strong); }
+    .warn .progress { border: 1px solid var(--warn-strong); }
+    .warn .progress > div { background: var(--warn-strong); }
+    .error .progress { border: 1px solid var(--error-strong); }
+    .error .progress > div { background: var(--error-strong); }
     .indicator { height: 10px; margin: 10px 0; }
-    .indicator.ok { background: rgb(94,145,53) }
-    .indicator.warn { background: rgb(242,202,83) }
-    .indicator.error { background: darkred }
-</style>
+    .indicator.ok { background: var(--ok-strong) }
+    .indicator.warn { background: var(--warn-strong) }
+    .indicator.error { background: var(--error-strong) }
+    .uncovered { background: var(--uncovered); }
+    .heat-1 { background: var(--heat-1); }
+    .heat-2 { background: var(--heat-2); }
+    .heat-3 { background: var(--heat-3); }
+    .heat-4 { background: var(--heat-4); color: var(--heat-text); }
+    .tests { text-decoration: underline dotted; cursor: help; }
+`
+
+var defaultTemplate = template.Must(template.New("page").Parse(tmpl))
+
+const tmpl = `<!doctype html>
+<head>
+<title>{{.Title}}</title>
+<style>
+{{.CSS}}</style>
 </head>
 <body>
 <div class="breadcrumbs"></div>
 <div class="stats"></div>
 <div class="indicator"></div>
 <table class="table"></table>
-<!-- REPORT -->
-<!-- SOURCE -->
-<script>
-<!-- SCRIPT -->
+<script class="tree-data" type="application/json">{{.Tree}}</script>
+<script class="config-data" type="application/json">{{.Config}}</script>
+{{range .Sources}}<div class="source" id="{{.Path}}"><pre>{{.Code}}</pre></div>
+{{end}}<script>
+{{.Script}}
 </script>
 </body>
 `
@@ -7,14 +7,29 @@ import (
 )
 
 func (cmd *Cmd) Test() {
-	coverArgs := []string{"test", "-coverprofile", "coverage.out", "-coverpkg", "./...", "./..."}
+	if err := cmd.runTests("coverage.out"); err != nil {
+		_, _ = fmt.Fprintf(cmd.stderr, "failed to run `go test` command: %s", err.Error())
+		cmd.exiter.Exit(1)
+		return
+	}
+
+	if !cmd.config.PerTest {
+		return
+	}
+	if err := cmd.testPerTest(); err != nil {
+		_, _ = fmt.Fprint(cmd.stderr, err.Error())
+		cmd.exiter.Exit(1)
+	}
+}
+
+// runTests runs all of the tests of the module with their coverage written
+// to the given profile.
+func (cmd *Cmd) runTests(profile string, extraArgs ...string) error {
+	coverArgs := append([]string{"test"}, extraArgs...)
+	coverArgs = append(coverArgs, "-coverprofile", profile, "-coverpkg", "./...", "./...")
 	_, _ = fmt.Fprintf(cmd.stdout, "executing: go %s\n", strings.Join(coverArgs, " "))
 	execCmd := exec.Command("go", coverArgs...)
 	execCmd.Stdout = cmd.stdout
 	execCmd.Stderr = cmd.stderr
-	err := execCmd.Run()
-	if err != nil {
-		_, _ = fmt.Fprintf(cmd.stderr, "failed to run `go test` command: %s", err.Error())
-		cmd.exiter.Exit(1)
-	}
+	return execCmd.Run()
 }
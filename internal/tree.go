@@ -3,8 +3,8 @@ package internal
 import (
 	"fmt"
 	"io"
-	"io/fs"
 	"sort"
+	"strconv"
 	"strings"
 )
 
@@ -13,6 +13,13 @@ const (
 	percentEmptySymbol = " "
 )
 
+const (
+	SortByName      = "name"
+	SortByPercent   = "percent"
+	SortByUncovered = "uncovered"
+	SortByStmts     = "stmts"
+)
+
 type Tree struct {
 	Root   *Node
 	writer io.Writer
@@ -26,49 +33,71 @@ func NewTree(w io.Writer) *Tree {
 }
 
 func (t *Tree) Render(config *Config, stats Stats, args []string) {
-	w := t.writer
-	_, _ = fmt.Fprintf(w, "|-%s-|-%s-|-%s-|-%s|", strings.Repeat("-", stats.FileMaxLen), strings.Repeat("-", stats.StmtsMaxLen+1), strings.Repeat("-", 8), strings.Repeat("-", 11))
-	if config.WithFullPath {
-		_, _ = fmt.Fprintf(w, "-%s-|", strings.Repeat("-", stats.FullPathMaxLen))
-	}
-	_, _ = fmt.Fprintf(w, "\n")
-	_, _ = fmt.Fprintf(w, "| %-*s | %*s | %*s | %-*s |", stats.FileMaxLen, "File", stats.StmtsMaxLen+1, "Stmts", 8, "% Stmts", 10, "Progress")
-	if config.WithFullPath {
-		_, _ = fmt.Fprintf(w, " %-*s |", stats.FullPathMaxLen, "Full path")
-	}
-	_, _ = fmt.Fprintf(w, "\n")
-	_, _ = fmt.Fprintf(w, "|-%s-|-%s-|-%s-|-%s-|", strings.Repeat("-", stats.FileMaxLen), strings.Repeat("-", stats.StmtsMaxLen+1), strings.Repeat("-", 8), strings.Repeat("-", 10))
-	if config.WithFullPath {
-		_, _ = fmt.Fprintf(w, "-%s-|", strings.Repeat("-", stats.FullPathMaxLen))
+	if config.Flat {
+		t.renderFlat(config, stats, args)
+		return
 	}
-	_, _ = fmt.Fprintf(w, "\n")
 
-	sortOrder := make([]string, 0, len(t.Root.children))
-	for k := range t.Root.children {
-		sortOrder = append(sortOrder, k)
+	tbl := newTable(t.writer, config.Style, tableColumns(config, stats))
+	tbl.fit(config.Width)
+	tbl.header()
+
+	t.applyFilters(config, args)
+
+	for _, c := range t.Root.sortedChildren(config) {
+		c.render(tbl, config, args)
 	}
-	sort.Strings(sortOrder)
 
-	for _, k := range sortOrder {
-		c := t.Root.children[k]
-		c.Render(w, config, stats, args)
+	tbl.footer()
+}
+
+const uncoveredLinesTitle = "Uncovered Line #s"
+
+func tableColumns(config *Config, stats Stats) []*column {
+	columns := []*column{
+		{title: "File", width: stats.FileMaxLen, minWidth: len("File")},
+		{title: "Stmts", width: stats.StmtsMaxLen + 1, right: true},
+		{title: "% Stmts", width: 8, right: true},
+	}
+	if config.Lines {
+		columns = append(columns, &column{title: "% Lines", width: 8, right: true})
+	}
+	if config.Branches {
+		columns = append(columns, &column{title: "% Branch", width: 8, right: true})
 	}
-	_, _ = fmt.Fprintf(w, "|-%s-|-%s-|-%s-|-%s-|", strings.Repeat("-", stats.FileMaxLen), strings.Repeat("-", stats.StmtsMaxLen+1), strings.Repeat("-", 8), strings.Repeat("-", 10))
+	progress := &column{title: "Progress", width: len("Progress")}
+	if barWidth(config) > progress.width {
+		progress.width = barWidth(config)
+	}
+	columns = append(columns, progress)
 	if config.WithFullPath {
-		_, _ = fmt.Fprintf(w, "-%s-|", strings.Repeat("-", stats.FullPathMaxLen))
+		columns = append(columns, &column{title: "Full path", width: stats.FullPathMaxLen, minWidth: len("Full path")})
 	}
-	_, _ = fmt.Fprintf(w, "\n")
-
-	// fmt.Printf("/full/path/to/gocov/go.mod:1\n")
-	// fmt.Printf("gocov/go.mod:1\n")
+	if config.UncoveredLines {
+		columns = append(columns, &column{
+			title:    uncoveredLinesTitle,
+			width:    stats.UncoveredMaxLen,
+			minWidth: len(uncoveredLinesTitle),
+			truncate: true,
+		})
+		if columns[len(columns)-1].width < len(uncoveredLinesTitle) {
+			columns[len(columns)-1].width = len(uncoveredLinesTitle)
+		}
+	}
+	return columns
 }
 
 type Stats struct {
-	All            int
-	Covered        int
-	FileMaxLen     int
-	StmtsMaxLen    int
-	FullPathMaxLen int
+	All             int
+	Covered         int
+	Lines           int
+	LinesCovered    int
+	Branches        int
+	BranchesTaken   int
+	FileMaxLen      int
+	StmtsMaxLen     int
+	FullPathMaxLen  int
+	UncoveredMaxLen int
 }
 
 func (t *Tree) Accumulate() Stats {
@@ -80,14 +109,22 @@ func (t *Tree) Accumulate() Stats {
 }
 
 func (n *Node) Accumulate() Stats {
-	var all, covered, maxPathLength, maxStmtsLength, fullPathMaxLen int
+	var all, covered, lines, linesCovered, branches, branchesTaken, maxPathLength, maxStmtsLength, fullPathMaxLen, uncoveredMaxLen int
 	if n.value != nil {
 		all = n.value.AllStatements
 		covered = n.value.Covered
+		lines = n.value.Lines
+		linesCovered = n.value.LinesCovered
+		branches = n.value.Branches
+		branchesTaken = n.value.BranchesTaken
+		n.uncovered = uncoveredRanges(n.value)
+		uncoveredMaxLen = len(n.uncovered)
 	}
 	for _, cn := range n.children {
 		stats := cn.Accumulate()
 		all, covered = all+stats.All, covered+stats.Covered
+		lines, linesCovered = lines+stats.Lines, linesCovered+stats.LinesCovered
+		branches, branchesTaken = branches+stats.Branches, branchesTaken+stats.BranchesTaken
 		if stats.FileMaxLen > maxPathLength {
 			maxPathLength = stats.FileMaxLen
 		}
@@ -97,9 +134,16 @@ func (n *Node) Accumulate() Stats {
 		if stats.FullPathMaxLen > fullPathMaxLen {
 			fullPathMaxLen = stats.FullPathMaxLen
 		}
+		if stats.UncoveredMaxLen > uncoveredMaxLen {
+			uncoveredMaxLen = stats.UncoveredMaxLen
+		}
 	}
 	n.allStatements = all
 	n.covered = covered
+	n.lines = lines
+	n.linesCovered = linesCovered
+	n.branches = branches
+	n.branchesTaken = branchesTaken
 	pathLength := (n.level * 2) + len(n.path)
 	if pathLength > maxPathLength {
 		maxPathLength = pathLength
@@ -113,29 +157,37 @@ func (n *Node) Accumulate() Stats {
 		fullPathMaxLen = fullPathLen
 	}
 	return Stats{
-		All:            all,
-		Covered:        covered,
-		FileMaxLen:     maxPathLength,
-		StmtsMaxLen:    maxStmtsLength,
-		FullPathMaxLen: fullPathMaxLen,
+		All:             all,
+		Covered:         covered,
+		Lines:           lines,
+		LinesCovered:    linesCovered,
+		Branches:        branches,
+		BranchesTaken:   branchesTaken,
+		FileMaxLen:      maxPathLength,
+		StmtsMaxLen:     maxStmtsLength,
+		FullPathMaxLen:  fullPathMaxLen,
+		UncoveredMaxLen: uncoveredMaxLen,
 	}
 }
 
-func getColor(percent float64) (string, string) {
+func getColor(percent float64, watermarks Watermarks) (string, string) {
 	color := Red
 	noColorValue := NoColor
-	if percent >= 80 {
+	if percent >= watermarks.High {
 		color = Green
-	} else if percent >= 50 {
+	} else if percent >= watermarks.Low {
 		color = Yellow
 	}
 	return color, noColorValue
 }
 
-func (n *Node) Render(w io.Writer, config *Config, stats Stats, args []string) {
+func (n *Node) render(tbl *table, config *Config, args []string) {
 	if config.Depth != 0 && n.level > config.Depth {
 		return
 	}
+	if n.hidden {
+		return
+	}
 	var filterBySelectedPath bool
 	var found bool
 	if len(args) > 0 {
@@ -147,38 +199,125 @@ func (n *Node) Render(w io.Writer, config *Config, stats Stats, args []string) {
 		}
 	}
 
+	if !filterBySelectedPath || found || n.level == 0 {
+		n.renderRow(tbl, config, strings.Repeat("  ", n.level)+n.path)
+	}
+	for _, c := range n.sortedChildren(config) {
+		c.render(tbl, config, args)
+	}
+}
+
+func (n *Node) renderRow(tbl *table, config *Config, label string) {
 	percent := getPercent(n)
-	color, noColorValue := getColor(percent)
-	if !config.Color {
-		color = ""
-		noColorValue = ""
+	color, noColorValue := getColor(percent, config.Watermarks)
+	color, noColorValue = config.paint(color), config.paint(noColorValue)
+	width := barWidth(config)
+	filled := progressbar(percent, width)
+	cells := []string{
+		label,
+		fmt.Sprintf("%d/%d", n.covered, n.allStatements),
+		fmt.Sprintf("%7.2f%%", percent),
 	}
-	stmtsPadding := stats.StmtsMaxLen - digitsCount(n.allStatements) - digitsCount(n.covered)
-	if !filterBySelectedPath || found || n.level == 0 {
-		_, _ = fmt.Fprintf(w,
-			"|%s%s %s%s %s| %s%s%d/%d%s | %s%7.2f%%%s | %s%s%s |",
-			color, strings.Repeat("  ", n.level), n.path, padPath(stats.FileMaxLen, n.path, n.level), noColorValue,
-			color, strings.Repeat(" ", stmtsPadding), n.covered, n.allStatements, noColorValue,
-			color, percent, noColorValue,
-			color, strings.Repeat(percentFillSymbol, progressbar(percent))+strings.Repeat(percentEmptySymbol, 10-progressbar(percent)), noColorValue,
-		)
-		if config.WithFullPath {
-			_, _ = fmt.Fprintf(w,
-				" %s%-*s%s |",
-				color, stats.FullPathMaxLen, n.fullPath, noColorValue,
-			)
+	if config.Lines {
+		cells = append(cells, fmt.Sprintf("%7.2f%%", getLinesPercent(n)))
+	}
+	if config.Branches {
+		cells = append(cells, fmt.Sprintf("%7.2f%%", getBranchPercent(n)))
+	}
+	cells = append(cells,
+		strings.Repeat(barSymbol(config), filled)+strings.Repeat(percentEmptySymbol, width-filled),
+	)
+	if config.WithFullPath {
+		cells = append(cells, n.fullPath)
+	}
+	if config.UncoveredLines {
+		cells = append(cells, n.uncovered)
+	}
+	tbl.row(cells, color, noColorValue)
+}
+
+// renderFlat renders one row per file, labelled with its module relative path
+// and the first uncovered line so that terminals and editors can jump to it.
+func (t *Tree) renderFlat(config *Config, stats Stats, args []string) {
+	var (
+		files  []*Node
+		labels = map[*Node]string{}
+	)
+	stats.FileMaxLen = len("File")
+	for _, n := range t.filteredFiles(config, args) {
+		label := fmt.Sprintf("%s:%d", getPath(n.fullPath), firstUncoveredLine(n.value))
+		if len(label) > stats.FileMaxLen {
+			stats.FileMaxLen = len(label)
+		}
+		labels[n] = label
+		files = append(files, n)
+	}
+
+	tbl := newTable(t.writer, config.Style, tableColumns(config, stats))
+	tbl.fit(config.Width)
+	tbl.header()
+	for _, n := range files {
+		n.renderRow(tbl, config, labels[n])
+	}
+	tbl.footer()
+}
+
+func matchesSelectedPath(n *Node, args []string) bool {
+	if len(args) == 0 {
+		return true
+	}
+	for _, search := range args {
+		if strings.HasPrefix(n.fullPath, search) {
+			return true
+		}
+	}
+	return false
+}
+
+func firstUncoveredLine(file *covFile) int {
+	line := 0
+	for _, report := range file.Reports {
+		if report.Hits == 0 && report.StatementsCount > 0 && (line == 0 || report.StartLine < line) {
+			line = report.StartLine
+		}
+	}
+	if line == 0 {
+		return 1
+	}
+	return line
+}
+
+// uncoveredRanges lists the lines of the uncovered blocks, merging the
+// adjacent lines into ranges, e.g. "12-18, 40, 77-80".
+func uncoveredRanges(file *covFile) string {
+	lines := map[int]bool{}
+	for _, report := range file.Reports {
+		if report.Hits > 0 || report.StatementsCount == 0 {
+			continue
+		}
+		for line := report.StartLine; line <= report.EndLine; line++ {
+			lines[line] = true
 		}
-		_, _ = fmt.Fprintf(w, "\n")
 	}
-	sortOrder := make([]string, 0, len(n.children))
-	for k := range n.children {
-		sortOrder = append(sortOrder, k)
+	sorted := make([]int, 0, len(lines))
+	for line := range lines {
+		sorted = append(sorted, line)
 	}
-	sort.Strings(sortOrder)
-	for _, k := range sortOrder {
-		c := n.children[k]
-		c.Render(w, config, stats, args)
+	sort.Ints(sorted)
+
+	var ranges []string
+	for i := 0; i < len(sorted); i++ {
+		first := sorted[i]
+		for i+1 < len(sorted) && sorted[i+1] == sorted[i]+1 {
+			i++
+		}
+		if first == sorted[i] {
+			ranges = append(ranges, strconv.Itoa(first))
+			continue
+		}
+		ranges = append(ranges, fmt.Sprintf("%d-%d", first, sorted[i]))
 	}
+	return strings.Join(ranges, ", ")
 }
 
 type Node struct {
@@ -187,8 +326,134 @@ type Node struct {
 	value         *covFile
 	allStatements int
 	covered       int
+	lines         int
+	linesCovered  int
+	branches      int
+	branchesTaken int
 	children      map[string]*Node
 	level         int
+	hidden        bool
+	uncovered     string
+}
+
+func (n *Node) sortedChildren(config *Config) []*Node {
+	children := make([]*Node, 0, len(n.children))
+	for _, c := range n.children {
+		children = append(children, c)
+	}
+	sort.Slice(children, func(i, j int) bool {
+		return lessNodes(children[i], children[j], config)
+	})
+	return children
+}
+
+// lessNodes orders nodes by the configured sort key. Every key except the
+// name puts the nodes which need the most attention first, ties are broken
+// by name so that the output stays stable.
+func lessNodes(a, b *Node, config *Config) bool {
+	if config.Reverse {
+		a, b = b, a
+	}
+	switch config.SortBy {
+	case SortByPercent:
+		if pa, pb := getPercent(a), getPercent(b); pa != pb {
+			return pa < pb
+		}
+	case SortByUncovered:
+		if ua, ub := a.allStatements-a.covered, b.allStatements-b.covered; ua != ub {
+			return ua > ub
+		}
+	case SortByStmts:
+		if a.allStatements != b.allStatements {
+			return a.allStatements > b.allStatements
+		}
+	}
+	return a.path < b.path
+}
+
+func isValidSortKey(key string) bool {
+	switch key {
+	case "", SortByName, SortByPercent, SortByUncovered, SortByStmts:
+		return true
+	}
+	return false
+}
+
+func hasFilters(config *Config) bool {
+	return config.MinPercent != nil || config.MaxPercent != nil || config.OnlyUncovered || config.Top != 0
+}
+
+func isFileFiltered(n *Node, config *Config) bool {
+	percent := getPercent(n)
+	if config.MinPercent != nil && percent < *config.MinPercent {
+		return true
+	}
+	if config.MaxPercent != nil && percent > *config.MaxPercent {
+		return true
+	}
+	if config.OnlyUncovered && n.allStatements > 0 && n.covered == n.allStatements {
+		return true
+	}
+	return false
+}
+
+// applyFilters hides the files which do not match the filter options and the
+// directories left without any visible files. The totals are accumulated
+// beforehand, so directories still report the numbers for all of their files.
+func (t *Tree) applyFilters(config *Config, args []string) {
+	if !hasFilters(config) {
+		return
+	}
+	files := t.filteredFiles(config, args)
+	visible := make(map[*Node]bool, len(files))
+	for _, f := range files {
+		visible[f] = true
+	}
+	t.Root.markVisible(visible)
+}
+
+// filteredFiles returns the file nodes which match the filter options and the
+// selected paths, in the configured sort order and limited to the top N files
+// if requested. The flat list has no directories to stop at, so its files
+// deeper than the depth limit are left out here as well.
+func (t *Tree) filteredFiles(config *Config, args []string) []*Node {
+	var files []*Node
+	t.Root.walk(func(n *Node) {
+		if config.Flat && config.Depth != 0 && n.level > config.Depth {
+			return
+		}
+		if n.value != nil && !isFileFiltered(n, config) && matchesSelectedPath(n, args) {
+			files = append(files, n)
+		}
+	})
+	sort.Slice(files, func(i, j int) bool {
+		return lessNodes(files[i], files[j], config)
+	})
+	if config.Top > 0 && len(files) > config.Top {
+		files = files[:config.Top]
+	}
+	return files
+}
+
+func (n *Node) walk(fn func(n *Node)) {
+	for _, c := range n.children {
+		fn(c)
+		c.walk(fn)
+	}
+}
+
+func (n *Node) markVisible(visible map[*Node]bool) bool {
+	var found bool
+	for _, c := range n.children {
+		if c.markVisible(visible) {
+			found = true
+		}
+	}
+	if visible[n] {
+		found = true
+	}
+	n.hidden = !found
+	return found
 }
 
 func (n *Node) Add(path string, fullPath string, value *covFile, level int) {
@@ -207,8 +472,50 @@ func (n *Node) Add(path string, fullPath string, value *covFile, level int) {
 	n.children[path[:index]].Add(path[index+1:], fullPath, value, level+1)
 }
 
-func progressbar(percent float64) int {
-	return int(percent / 10)
+// Compact collapses chains of directories with exactly one child directory
+// into a single `a/b/c` node. It has to be called before Accumulate, since
+// the column widths depend on the node levels.
+func (t *Tree) Compact() {
+	for _, c := range t.Root.children {
+		c.compact()
+	}
+}
+
+func (n *Node) compact() {
+	for n.value == nil && len(n.children) == 1 {
+		var child *Node
+		for _, c := range n.children {
+			child = c
+		}
+		if child.value != nil {
+			break
+		}
+		n.path += "/" + child.path
+		n.fullPath = child.fullPath
+		n.children = child.children
+		n.walk(func(c *Node) { c.level-- })
+	}
+	for _, c := range n.children {
+		c.compact()
+	}
+}
+
+func progressbar(percent float64, width int) int {
+	return int(percent * float64(width) / 100)
+}
+
+func barWidth(config *Config) int {
+	if config.BarWidth > 0 {
+		return config.BarWidth
+	}
+	return 10
+}
+
+func barSymbol(config *Config) string {
+	if config.BarSymbol != "" {
+		return config.BarSymbol
+	}
+	return percentFillSymbol
 }
 
 func getFullPath(path string, level int) string {
@@ -216,77 +523,79 @@ func getFullPath(path string, level int) string {
 	return strings.Join(parts[:level+1], "/")
 }
 
-func (t *Tree) RenderHTML(
-	cmd *Cmd,
-	sourceBuilder, objectBuilder io.Writer,
-	config *Config,
-	stats Stats,
-	args []string,
-	fsys fs.StatFS,
-	files map[string]*covFile,
-	moduleDir string,
-) {
-	sortOrder := make([]string, 0, len(t.Root.children))
-	for k := range t.Root.children {
-		sortOrder = append(sortOrder, k)
-	}
-	sort.Strings(sortOrder)
-	for _, k := range sortOrder {
-		c := t.Root.children[k]
-		c.RenderHTML(cmd, sourceBuilder, objectBuilder, config, stats, args, fsys, files, moduleDir)
-	}
-}
-
-func (n *Node) RenderHTML(
-	cmd *Cmd,
-	sourceBuilder, objectBuilder io.Writer,
-	config *Config,
-	stats Stats,
-	args []string,
-	fsys fs.StatFS,
-	files map[string]*covFile,
-	moduleDir string,
-) {
-	percent := getPercent(n)
-	pathToFile := getPath(n.fullPath)
+// RenderHTML returns the data of the tree and the sources of its files for
+// the html report.
+func (t *Tree) RenderHTML(cmd *Cmd) (*htmlNode, []*htmlSource, error) {
+	var sources []*htmlSource
+	children := t.Root.sortedChildren(cmd.config)
+	if len(children) == 1 {
+		node, err := children[0].RenderHTML(cmd, &sources)
+		return node, sources, err
+	}
+	// profiles of several modules are listed below a node of their own
+	node := t.Root.htmlNode(cmd.config, siteRootName)
+	node.Children = []*htmlNode{}
+	for _, c := range children {
+		child, err := c.RenderHTML(cmd, &sources)
+		if err != nil {
+			return nil, nil, err
+		}
+		node.Children = append(node.Children, child)
+	}
+	return node, sources, nil
+}
 
-	if strings.HasSuffix(pathToFile, ".go") {
-		colorizedSourceFile, err := cmd.inspect([]string{n.fullPath}, files, moduleDir)
+// RenderHTML returns the data of the node and its children, appending the
+// sources of its files.
+func (n *Node) RenderHTML(cmd *Cmd, sources *[]*htmlSource) (*htmlNode, error) {
+	node := n.htmlNode(cmd.config, n.path)
+	if n.value != nil {
+		source, err := cmd.htmlSource(n)
 		if err != nil {
-			_, _ = fmt.Fprintf(cmd.stderr, "failed to inspect file: %s", err.Error())
-			cmd.exiter.Exit(1)
-			return
+			return nil, err
 		}
-		escaped := escape(colorizedSourceFile)
-		_, _ = fmt.Fprintf(sourceBuilder, `<div class="source" id="%s"><pre>%s</pre></div>`+"\n", n.fullPath, escaped)
+		*sources = append(*sources, source)
+		return node, nil
 	}
 
-	_, _ = fmt.Fprintf(objectBuilder, `{`)
-	_, _ = fmt.Fprintf(objectBuilder,
-		`"name":"%s","all":%d,"covered":%d,"percent":%.2f,"path":"%s","level":%d,`,
-		n.path, n.allStatements, n.covered, percent, n.fullPath, n.level,
-	)
-	if strings.HasSuffix(pathToFile, ".go") {
-		_, _ = fmt.Fprintf(objectBuilder, `"type":"file"}`)
-	} else {
-		_, _ = fmt.Fprintf(objectBuilder, `"type":"directory","children":[`)
+	node.Children = []*htmlNode{}
+	for _, c := range n.sortedChildren(cmd.config) {
+		child, err := c.RenderHTML(cmd, sources)
+		if err != nil {
+			return nil, err
+		}
+		node.Children = append(node.Children, child)
 	}
+	return node, nil
+}
 
-	sortOrder := make([]string, 0, len(n.children))
-	for k := range n.children {
-		sortOrder = append(sortOrder, k)
+// htmlNode returns the data of the node alone, without its children.
+func (n *Node) htmlNode(config *Config, name string) *htmlNode {
+	node := &htmlNode{
+		Name:         name,
+		All:          n.allStatements,
+		Covered:      n.covered,
+		Percent:      htmlPercent(getPercent(n)),
+		Lines:        n.lines,
+		LinesCovered: n.linesCovered,
+		LinesPercent: htmlPercent(getLinesPercent(n)),
+		Path:         n.fullPath,
+		Level:        n.level,
+		Type:         "directory",
 	}
-	sort.Strings(sortOrder)
-	for i, k := range sortOrder {
-		if i != 0 {
-			_, _ = fmt.Fprintf(objectBuilder, ",")
+	if config.Branches {
+		node.htmlBranches = &htmlBranches{
+			Branches:      n.branches,
+			BranchesTaken: n.branchesTaken,
+			BranchPercent: htmlPercent(getBranchPercent(n)),
 		}
-		c := n.children[k]
-		c.RenderHTML(cmd, sourceBuilder, objectBuilder, config, stats, args, fsys, files, moduleDir)
 	}
-	if !strings.HasSuffix(pathToFile, ".go") {
-		_, _ = fmt.Fprintf(objectBuilder, "]}")
+	if n.value != nil {
+		uncovered := n.uncovered
+		node.Type = "file"
+		node.UncoveredLines = &uncovered
 	}
+	return node
 }
 
 func getPath(fullPath string) string {